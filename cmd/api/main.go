@@ -52,6 +52,13 @@ func main() {
 	// it won't block the graceful shutdown handling below
 	go run(srv)
 
+	// Run the scheduled provider sync until shutdown
+	jobsDone := make(chan struct{})
+	go func() {
+		router.RunBackgroundJobs(ctx)
+		close(jobsDone)
+	}()
+
 	logger.Info("Server is running on port: ", router.Port)
 	// Listen for the interrupt signal.
 	<-ctx.Done()
@@ -68,5 +75,11 @@ func main() {
 		log.Fatal("Server forced to shutdown: ", err)
 	}
 
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		log.Println("sync job still running, exiting anyway")
+	}
+
 	log.Println("Server exiting")
 }
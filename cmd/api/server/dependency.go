@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"todo-planning/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type dependencyRequest struct {
+	TaskID      uint `json:"task_id" binding:"required"`
+	DependsOnID uint `json:"depends_on_id" binding:"required"`
+}
+
+func (s *Server) GetDependencies(c *gin.Context) {
+	dependencies, err := s.taskService.GetDependencies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get task dependencies",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dependencies": dependencies,
+	})
+}
+
+func (s *Server) CreateDependency(c *gin.Context) {
+	var request dependencyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request, expected task_id and depends_on_id",
+		})
+
+		return
+	}
+
+	dependency, err := s.taskService.AddDependency(request.TaskID, request.DependsOnID)
+	switch {
+	case errors.Is(err, service.ErrDependencyCycle):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Task not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create task dependency",
+		})
+	default:
+		c.JSON(http.StatusCreated, dependency)
+	}
+}
+
+func (s *Server) DeleteDependency(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid dependency id",
+		})
+
+		return
+	}
+
+	err = s.taskService.RemoveDependency(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Task dependency not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete task dependency",
+		})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
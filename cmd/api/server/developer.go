@@ -0,0 +1,275 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"todo-planning/internal/model"
+	"todo-planning/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type developerRequest struct {
+	Name           string   `json:"name" binding:"required"`
+	Productivity   *float64 `json:"productivity" binding:"required"`
+	WeeklyCapacity *float64 `json:"weekly_capacity"` // hours per week, 0 is a valid capacity
+}
+
+func (r developerRequest) developer() *model.Developer {
+	return &model.Developer{
+		Name:           r.Name,
+		Productivity:   *r.Productivity,
+		WeeklyCapacity: r.WeeklyCapacity,
+	}
+}
+
+func (s *Server) GetDevelopers(c *gin.Context) {
+	developers, err := s.developerService.GetDevelopers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get developers",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"developers": developers,
+	})
+}
+
+func (s *Server) GetDeveloper(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid developer id",
+		})
+
+		return
+	}
+
+	developer, err := s.developerService.GetDeveloper(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Developer not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get developer",
+		})
+	default:
+		c.JSON(http.StatusOK, developer)
+	}
+}
+
+func (s *Server) CreateDeveloper(c *gin.Context) {
+	var request developerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request, expected name and productivity",
+		})
+
+		return
+	}
+
+	developer := request.developer()
+	err := s.developerService.CreateDeveloper(developer)
+	s.renderDeveloper(c, developer, err, http.StatusCreated)
+}
+
+func (s *Server) UpdateDeveloper(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid developer id",
+		})
+
+		return
+	}
+
+	var request developerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request, expected name and productivity",
+		})
+
+		return
+	}
+
+	developer := request.developer()
+	developer.ID = uint(id)
+	err = s.developerService.UpdateDeveloper(developer)
+	s.renderDeveloper(c, developer, err, http.StatusOK)
+}
+
+func (s *Server) DeleteDeveloper(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid developer id",
+		})
+
+		return
+	}
+
+	err = s.developerService.DeleteDeveloper(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Developer not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete developer",
+		})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (s *Server) renderDeveloper(c *gin.Context, developer *model.Developer, err error, status int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDeveloper):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrDuplicateDeveloper):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Developer not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save developer",
+		})
+	default:
+		c.JSON(status, developer)
+	}
+}
+
+type availabilityRequest struct {
+	Hours  *float64 `json:"hours" binding:"required"`
+	Reason string   `json:"reason"`
+}
+
+func (s *Server) GetDeveloperAvailability(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid developer id",
+		})
+
+		return
+	}
+
+	availability, err := s.developerService.GetAvailability(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Developer not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get developer availability",
+		})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"availability": availability,
+		})
+	}
+}
+
+func (s *Server) SetDeveloperAvailability(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid developer id",
+		})
+
+		return
+	}
+
+	week, err := strconv.Atoi(c.Param("week"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid week number",
+		})
+
+		return
+	}
+
+	var request availabilityRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request, expected hours",
+		})
+
+		return
+	}
+
+	availability := &model.DeveloperAvailability{
+		DeveloperID: uint(id),
+		WeekNumber:  week,
+		Hours:       *request.Hours,
+		Reason:      request.Reason,
+	}
+
+	err = s.developerService.SetAvailability(availability)
+	switch {
+	case errors.Is(err, service.ErrInvalidAvailability):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Developer not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set developer availability",
+		})
+	default:
+		c.JSON(http.StatusOK, availability)
+	}
+}
+
+func (s *Server) DeleteDeveloperAvailability(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid developer id",
+		})
+
+		return
+	}
+
+	week, err := strconv.Atoi(c.Param("week"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid week number",
+		})
+
+		return
+	}
+
+	err = s.developerService.RemoveAvailability(uint(id), week)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Developer availability not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to remove developer availability",
+		})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -1,16 +1,132 @@
 package server
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 	"todo-planning/internal/model"
+	"todo-planning/internal/planner"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+type planResponse struct {
+	PlanRunID    uint                         `json:"planRunId,omitempty"`
+	Version      int                          `json:"version,omitempty"`
+	Sorter       string                       `json:"sorter"`
+	SorterSeed   *uint64                      `json:"sorterSeed,omitempty"`
+	Solver       string                       `json:"solver"`
+	Optimal      bool                         `json:"optimal"`
+	Dependencies string                       `json:"dependencies"`
+	CreatedAt    string                       `json:"createdAt,omitempty"`
+	Assignments  [][]model.AssignmentResponse `json:"assignments"`
+	Splits       []model.TaskSplit            `json:"splits"`
+	Unassigned   []model.UnassignedTask       `json:"unassigned"`
+	TotalHours   float64                      `json:"totalHours"`
+	TotalWeeks   int                          `json:"totalWeeks"`
+}
+
+func newPlanResponse(run *model.PlanRun) planResponse {
+	response := planResponse{
+		PlanRunID:    run.ID,
+		Version:      run.Version,
+		Sorter:       run.Sorter,
+		SorterSeed:   run.SorterSeed,
+		Solver:       run.Solver,
+		Optimal:      run.Optimal,
+		Dependencies: run.Dependencies,
+		Assignments:  make([][]model.AssignmentResponse, 0),
+		Splits:       make([]model.TaskSplit, 0),
+		Unassigned:   make([]model.UnassignedTask, 0),
+		TotalHours:   run.TotalHours,
+		TotalWeeks:   run.TotalWeeks,
+	}
+
+	response.Splits = append(response.Splits, run.Splits...)
+	response.Unassigned = append(response.Unassigned, run.Unassigned...)
+
+	if !run.CreatedAt.IsZero() {
+		response.CreatedAt = run.CreatedAt.Format(time.RFC3339)
+	}
+
+	developerAssignments := make(map[uint][]model.AssignmentResponse)
+
+	// Convert assignments to response format
+	for _, assignment := range run.Assignments {
+		developerAssignments[assignment.DeveloperID] = append(developerAssignments[assignment.DeveloperID], model.AssignmentResponse{
+			TaskName:        assignment.Task.DisplayName(),
+			WeekNumber:      assignment.WeekNumber,
+			CalculatedHours: assignment.CalculatedHours,
+			Task:            assignment.Task,
+			Developer:       assignment.Developer,
+		})
+	}
+
+	for _, assignments := range developerAssignments {
+		response.Assignments = append(response.Assignments, assignments)
+	}
+
+	return response
+}
+
+// GetPlan returns a preview of a new plan without storing anything
 func (s *Server) GetPlan(c *gin.Context) {
-	// Get the plan
-	assignments, err := s.planner.Plan()
+	s.plan(c, true)
+}
+
+// CreatePlan creates a new plan and stores it as a plan run together with
+// the sub-tasks of split tasks
+func (s *Server) CreatePlan(c *gin.Context) {
+	s.plan(c, false)
+}
+
+func (s *Server) plan(c *gin.Context, preview bool) {
+	request := planner.PlanRequest{
+		Preview:          preview,
+		Solver:           c.Query("solver"),
+		DisableSplitting: c.Query("split") == "false",
+		Dependencies:     c.Query("dependencies"),
+		Sorter:           c.Query("sorter"),
+	}
+
+	if value := c.Query("seed"); value != "" {
+		seed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid seed, expected an unsigned integer",
+			})
+
+			return
+		}
+
+		request.Seed = &seed
+	}
+
+	if budget := c.Query("budget"); budget != "" {
+		timeBudget, err := time.ParseDuration(budget)
+		if err != nil || timeBudget <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid budget, expected a duration such as 500ms or 2s",
+			})
+
+			return
+		}
+
+		request.TimeBudget = timeBudget
+	}
+
+	run, err := s.planner.CreatePlan(request)
+
+	if errors.Is(err, planner.ErrUnknownSolver) || errors.Is(err, planner.ErrUnknownDependencyMode) ||
+		errors.Is(err, planner.ErrUnknownSorter) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -20,49 +136,113 @@ func (s *Server) GetPlan(c *gin.Context) {
 		return
 	}
 
-	if len(assignments) == 0 {
-		c.JSON(http.StatusOK, gin.H{
-			"assignments": [][]model.AssignmentResponse{},
-			"totalHours":  0,
+	status := http.StatusCreated
+	if preview {
+		status = http.StatusOK
+	}
+
+	c.Header("Status", strconv.Itoa(status))
+	c.JSON(status, newPlanResponse(run))
+}
+
+func (s *Server) GetPlanRuns(c *gin.Context) {
+	runs, err := s.assignmentService.GetPlanRuns()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plan runs",
 		})
+
 		return
 	}
 
-	developerAssignments := make(map[uint][]model.AssignmentResponse)
+	c.JSON(http.StatusOK, gin.H{
+		"plans": runs,
+	})
+}
 
-	// Convert assignments to response format
-	response := struct {
-		Assignments [][]model.AssignmentResponse `json:"assignments"`
-		TotalHours  float64                      `json:"totalHours"`
-		TotalWeeks  int                          `json:"totalWeeks"`
-	}{
-		Assignments: make([][]model.AssignmentResponse, 0),
-	}
-
-	for _, assignment := range assignments {
-		taskName := fmt.Sprintf("Task %s - %s", assignment.Task.Source, assignment.Task.ExternalID)
-		if assignment.Task.Name != nil {
-			taskName = *assignment.Task.Name
-		}
+func (s *Server) GetPlanRun(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid plan id",
+		})
 
-		developerAssignments[assignment.DeveloperID] = append(developerAssignments[assignment.DeveloperID], model.AssignmentResponse{
-			TaskName:        taskName,
-			WeekNumber:      assignment.WeekNumber,
-			CalculatedHours: assignment.CalculatedHours,
-			Task:            assignment.Task,
-			Developer:       assignment.Developer,
+		return
+	}
+
+	run, err := s.assignmentService.GetPlanRun(uint(id))
+	s.renderPlanRun(c, run, err)
+}
+
+func (s *Server) GetLatestPlanRun(c *gin.Context) {
+	run, err := s.assignmentService.GetLatestPlanRun()
+	s.renderPlanRun(c, run, err)
+}
+
+// PushPlanRun writes the assignments of a plan run back to the sources of
+// its tasks, only describing the changes with dry_run=true
+func (s *Server) PushPlanRun(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid plan id",
 		})
 
-		if assignment.WeekNumber > response.TotalWeeks {
-			response.TotalWeeks = assignment.WeekNumber
-		}
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	pushes, err := s.pushService.PushPlanRun(c.Request.Context(), uint(id), dryRun)
+	s.renderPushes(c, pushes, err, "Failed to push assignments")
+}
+
+func (s *Server) GetPlanRunPushes(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid plan id",
+		})
 
-		response.TotalHours += assignment.CalculatedHours
+		return
 	}
 
-	for _, assignments := range developerAssignments {
-		response.Assignments = append(response.Assignments, assignments)
+	pushes, err := s.pushService.GetPushes(uint(id))
+	s.renderPushes(c, pushes, err, "Failed to get assignment pushes")
+}
+
+func (s *Server) renderPushes(c *gin.Context, pushes []model.AssignmentPush, err error, failure string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Plan not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": failure,
+		})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"pushes": append(make([]model.AssignmentPush, 0, len(pushes)), pushes...),
+		})
+	}
+}
+
+func (s *Server) renderPlanRun(c *gin.Context, run *model.PlanRun, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Plan not found",
+		})
+
+		return
 	}
-	c.Header("Status", "200")
-	c.JSON(http.StatusOK, response)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plan",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newPlanResponse(run))
 }
@@ -1,6 +1,10 @@
 package server
 
 import (
+	"context"
+
+	"todo-planning/internal/config"
+	"todo-planning/internal/logger"
 	"todo-planning/internal/planner"
 	"todo-planning/internal/service"
 
@@ -11,7 +15,15 @@ import (
 
 type Server struct {
 	*gin.Engine
-	planner *planner.Planner
+	planner           *planner.Planner
+	taskService       *service.TaskService
+	developerService  *service.DeveloperService
+	assignmentService *service.AssignmentService
+	syncService       *service.SyncService
+	syncScheduler     *service.SyncScheduler // nil unless the scheduled sync is enabled
+	webhookService    *service.WebhookService
+	pushService       *service.PushService
+	replans           chan struct{} // pending replan requested by a webhook
 
 	Port int
 }
@@ -23,6 +35,8 @@ func NewServer(port int, database *gorm.DB) *Server {
 		taskService := service.NewTaskService(database)
 		developerService := service.NewDeveloperService(database)
 		assignmentService := service.NewAssignmentService(database)
+		providerService := service.NewProviderService()
+		syncService := service.NewSyncService(database, providerService, taskService)
 
 		serverInstance = &Server{
 			Port: port,
@@ -30,11 +44,20 @@ func NewServer(port int, database *gorm.DB) *Server {
 				TaskService:       taskService,
 				DeveloperService:  developerService,
 				AssignmentService: assignmentService,
-				SaveAssignments:   false,
+				SaveAssignments:   true,
 				ChannelManager:    planner.NewDefaultChannelManager(),
 			}),
+			taskService:       taskService,
+			developerService:  developerService,
+			assignmentService: assignmentService,
+			syncService:       syncService,
+			webhookService:    service.NewWebhookService(taskService, providerService),
+			pushService:       service.NewPushService(database, providerService, assignmentService),
+			replans:           make(chan struct{}, 1),
 		}
 
+		serverInstance.syncScheduler = configureSync(syncService)
+
 		gin.SetMode(gin.ReleaseMode)
 
 		r := gin.Default()
@@ -57,4 +80,79 @@ func NewServer(port int, database *gorm.DB) *Server {
 func (s *Server) RegisterRoutes() {
 	api := s.Group("/api")
 	api.GET("/weekly-plan", s.GetPlan)
+	api.GET("/plans", s.GetPlanRuns)
+	api.POST("/plans", s.CreatePlan)
+	api.GET("/plans/latest", s.GetLatestPlanRun)
+	api.GET("/plans/:id", s.GetPlanRun)
+	api.POST("/plans/:id/push", s.PushPlanRun)
+	api.GET("/plans/:id/pushes", s.GetPlanRunPushes)
+	api.GET("/tasks", s.GetTasks)
+	api.POST("/tasks", s.CreateTask)
+	api.GET("/tasks/:id", s.GetTask)
+	api.PUT("/tasks/:id", s.UpdateTask)
+	api.DELETE("/tasks/:id", s.DeleteTask)
+	api.GET("/tasks/:id/revisions", s.GetTaskRevisions)
+	api.GET("/task-dependencies", s.GetDependencies)
+	api.POST("/task-dependencies", s.CreateDependency)
+	api.DELETE("/task-dependencies/:id", s.DeleteDependency)
+	api.POST("/sync", s.StartSync)
+	api.GET("/sync", s.GetSyncJobs)
+	api.GET("/sync/schedule", s.GetSyncSchedule)
+	api.GET("/sync/:id", s.GetSyncJob)
+	api.POST("/webhooks/:source", s.ReceiveWebhook)
+	api.GET("/developers", s.GetDevelopers)
+	api.POST("/developers", s.CreateDeveloper)
+	api.GET("/developers/:id", s.GetDeveloper)
+	api.PUT("/developers/:id", s.UpdateDeveloper)
+	api.DELETE("/developers/:id", s.DeleteDeveloper)
+	api.GET("/developers/:id/availability", s.GetDeveloperAvailability)
+	api.PUT("/developers/:id/availability/:week", s.SetDeveloperAvailability)
+	api.DELETE("/developers/:id/availability/:week", s.DeleteDeveloperAvailability)
+}
+
+// configureSync applies the sync settings of config.yaml and creates the
+// scheduled sync when it is enabled
+func configureSync(syncService *service.SyncService) *service.SyncScheduler {
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error(err)
+		return nil
+	}
+
+	if cfg.Sync.RemovalGracePeriod != nil {
+		syncService.SetRemovalGracePeriod(*cfg.Sync.RemovalGracePeriod)
+	}
+
+	if !cfg.Sync.Enabled {
+		return nil
+	}
+
+	scheduler, err := service.NewSyncScheduler(syncService, cfg.Sync.Schedule, cfg.Sync.Jitter)
+	if err != nil {
+		logger.Error(err)
+		return nil
+	}
+
+	return scheduler
+}
+
+// RunBackgroundJobs runs the scheduled sync, if enabled, and the replans
+// requested by webhooks until the context is cancelled. It then cancels a
+// running sync job and waits for it and a running replan to finish.
+func (s *Server) RunBackgroundJobs(ctx context.Context) {
+	replansDone := make(chan struct{})
+	go func() {
+		s.runReplans(ctx)
+		close(replansDone)
+	}()
+
+	if s.syncScheduler != nil {
+		s.syncScheduler.Run(ctx)
+	} else {
+		<-ctx.Done()
+	}
+
+	s.syncService.Cancel()
+	s.syncService.Wait()
+	<-replansDone
 }
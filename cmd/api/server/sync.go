@@ -0,0 +1,82 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"todo-planning/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// syncJobsLimit is the number of recent sync jobs listed by GetSyncJobs
+const syncJobsLimit = 20
+
+func (s *Server) StartSync(c *gin.Context) {
+	job, err := s.syncService.Start(service.TriggerAPI)
+	switch {
+	case errors.Is(err, service.ErrSyncInProgress):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"job":   job,
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to start sync",
+		})
+	default:
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+func (s *Server) GetSyncJobs(c *gin.Context) {
+	jobs, err := s.syncService.GetSyncJobs(syncJobsLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get sync jobs",
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs": jobs,
+	})
+}
+
+func (s *Server) GetSyncJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid sync job id",
+		})
+
+		return
+	}
+
+	job, err := s.syncService.GetSyncJob(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Sync job not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get sync job",
+		})
+	default:
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+func (s *Server) GetSyncSchedule(c *gin.Context) {
+	if s.syncScheduler == nil {
+		c.JSON(http.StatusOK, service.SchedulerStatus{Enabled: false})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, s.syncScheduler.Status())
+}
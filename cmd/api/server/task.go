@@ -0,0 +1,281 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"todo-planning/internal/model"
+	"todo-planning/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type createTaskRequest struct {
+	ExternalID        string   `json:"external_id"`
+	Name              *string  `json:"name" binding:"required"`
+	Difficulty        *float64 `json:"difficulty" binding:"required"`
+	EstimatedDuration *float64 `json:"estimated_duration" binding:"required"`
+	Priority          int      `json:"priority"`
+	Deadline          *string  `json:"deadline"`
+}
+
+type updateTaskRequest struct {
+	Name              *string  `json:"name"`
+	Difficulty        *float64 `json:"difficulty"`
+	EstimatedDuration *float64 `json:"estimated_duration"`
+	Priority          *int     `json:"priority"`
+	Deadline          *string  `json:"deadline"` // an empty string removes the deadline
+}
+
+type taskListResponse struct {
+	Tasks  []model.Task `json:"tasks"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
+
+func (s *Server) GetTasks(c *gin.Context) {
+	filter := service.TaskFilter{
+		Source: c.Query("source"),
+	}
+
+	floats := map[string]**float64{
+		"min_difficulty": &filter.MinDifficulty,
+		"max_difficulty": &filter.MaxDifficulty,
+		"min_duration":   &filter.MinDuration,
+		"max_duration":   &filter.MaxDuration,
+	}
+	for name, target := range floats {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid " + name + ", expected a number",
+				})
+
+				return
+			}
+
+			*target = &parsed
+		}
+	}
+
+	ints := map[string]*int{
+		"limit":  &filter.Limit,
+		"offset": &filter.Offset,
+	}
+	for name, target := range ints {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid " + name + ", expected a non-negative integer",
+				})
+
+				return
+			}
+
+			*target = parsed
+		}
+	}
+
+	tasks, total, err := s.taskService.ListTasks(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get tasks",
+		})
+
+		return
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = service.DefaultTaskLimit
+	}
+
+	c.JSON(http.StatusOK, taskListResponse{
+		Tasks:  tasks,
+		Total:  total,
+		Limit:  min(limit, service.MaxTaskLimit),
+		Offset: filter.Offset,
+	})
+}
+
+func (s *Server) GetTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task id",
+		})
+
+		return
+	}
+
+	task, err := s.taskService.GetTask(uint(id))
+	s.renderTask(c, task, err, http.StatusOK)
+}
+
+func (s *Server) CreateTask(c *gin.Context) {
+	var request createTaskRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request, expected name, difficulty and estimated_duration",
+		})
+
+		return
+	}
+
+	deadline, err := parseDeadline(request.Deadline)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	task := &model.Task{
+		ExternalID:        request.ExternalID,
+		Name:              request.Name,
+		Difficulty:        *request.Difficulty,
+		EstimatedDuration: *request.EstimatedDuration,
+		Priority:          request.Priority,
+		Deadline:          deadline,
+	}
+
+	err = s.taskService.CreateTask(task)
+	s.renderTask(c, task, err, http.StatusCreated)
+}
+
+func (s *Server) UpdateTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task id",
+		})
+
+		return
+	}
+
+	var request updateTaskRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request, expected name, difficulty or estimated_duration",
+		})
+
+		return
+	}
+
+	deadline, err := parseDeadline(request.Deadline)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	task, err := s.taskService.UpdateTask(uint(id), service.TaskUpdate{
+		Name:              request.Name,
+		Difficulty:        request.Difficulty,
+		EstimatedDuration: request.EstimatedDuration,
+		Priority:          request.Priority,
+		Deadline:          deadline,
+		ClearDeadline:     request.Deadline != nil && *request.Deadline == "",
+	})
+	s.renderTask(c, task, err, http.StatusOK)
+}
+
+func (s *Server) DeleteTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task id",
+		})
+
+		return
+	}
+
+	err = s.taskService.DeleteTask(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Task not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete task",
+		})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (s *Server) GetTaskRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task id",
+		})
+
+		return
+	}
+
+	revisions, err := s.taskService.GetRevisions(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Task not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get task revisions",
+		})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"revisions": revisions,
+		})
+	}
+}
+
+func (s *Server) renderTask(c *gin.Context, task *model.Task, err error, status int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTask):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrDuplicateTask):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Task not found",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save task",
+		})
+	default:
+		c.JSON(status, task)
+	}
+}
+
+// parseDeadline reads a deadline given as a date (2006-01-02) or an RFC 3339
+// timestamp, nil and empty strings give no deadline
+func parseDeadline(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if deadline, err := time.Parse(layout, *value); err == nil {
+			return &deadline, nil
+		}
+	}
+
+	return nil, errors.New("Invalid deadline, expected a date such as 2024-05-31 or an RFC 3339 timestamp")
+}
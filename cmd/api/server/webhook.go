@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"todo-planning/internal/logger"
+	"todo-planning/internal/planner"
+	"todo-planning/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody bounds the size of a webhook payload
+const maxWebhookBody = 1 << 20
+
+func (s *Server) ReceiveWebhook(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": "Webhook payload is too large",
+		})
+
+		return
+	}
+
+	result, err := s.webhookService.Handle(c.Param("source"), c.Request.Header, body)
+	switch {
+	case errors.Is(err, service.ErrUnknownWebhook):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidSignature):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidWebhook), errors.Is(err, service.ErrInvalidTask):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to apply webhook",
+		})
+	default:
+		if result.Replan {
+			s.requestReplan()
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// requestReplan asks for a new plan without waiting for it. Requests made
+// while one is pending are folded into it.
+func (s *Server) requestReplan() {
+	select {
+	case s.replans <- struct{}{}:
+	default:
+	}
+}
+
+// runReplans creates the requested plans one after another until the
+// context is cancelled
+func (s *Server) runReplans(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.replans:
+			if _, err := s.planner.CreatePlan(planner.PlanRequest{}); err != nil {
+				logger.Error(fmt.Errorf("failed to replan after a webhook: %w", err))
+			}
+		}
+	}
+}
@@ -4,17 +4,21 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"todo-planning/internal/config"
 	"todo-planning/internal/db"
 	"todo-planning/internal/logger"
 	"todo-planning/internal/model"
+	"todo-planning/internal/provider"
 	"todo-planning/internal/service"
 )
 
 func main() {
 	// Check if a subcommand is provided
 	if len(os.Args) < 2 {
-		fmt.Println("expected 'fetch' or 'init-db' subcommand")
+		fmt.Println("expected 'fetch', 'import' or 'init-db' subcommand")
 		os.Exit(1)
 	}
 
@@ -23,6 +27,16 @@ func main() {
 		fetchCmd := flag.NewFlagSet("fetch", flag.ExitOnError)
 		fetchCmd.Parse(os.Args[2:])
 		fetchTasks()
+	case "import":
+		importCmd := flag.NewFlagSet("import", flag.ExitOnError)
+		file := importCmd.String("file", "", "CSV, JSON or YAML file of tasks")
+		format := importCmd.String("format", "", "File format: csv, json or yaml (default: from the file extension)")
+		source := importCmd.String("source", "import", "Source of the imported tasks")
+		columns := importCmd.String("columns", "", "Column mapping, e.g. id=Key,name=Summary,difficulty=Points,duration=Hours")
+		durationUnit := importCmd.String("duration-unit", "", "Unit of the duration column: seconds, minutes, hours, days or weeks (default: hours)")
+		skipInvalid := importCmd.Bool("skip-invalid", false, "Import the valid rows even if some rows are invalid")
+		importCmd.Parse(os.Args[2:])
+		importTasks(*file, *format, *source, *columns, *durationUnit, *skipInvalid)
 	case "init-db":
 		initDBCmd := flag.NewFlagSet("init-db", flag.ExitOnError)
 		force := initDBCmd.Bool("force", false, "Force initialization even if developers exist")
@@ -41,21 +55,27 @@ func fetchTasks() {
 		os.Exit(1)
 	}
 
-	// Fetch tasks from providers
-	providerService := service.NewProviderService()
-	tasks, err := providerService.FetchTasksFromProviders()
-	if err != nil {
-		logger.Error(fmt.Errorf("failed to fetch tasks from providers: %w", err))
+	// Make sure the sync tables exist on databases initialized by older versions
+	if err := db.AutoMigrate(database); err != nil {
+		logger.Error(fmt.Errorf("failed to run migrations: %w", err))
 		os.Exit(1)
 	}
 
-	// Store tasks in database
 	taskService := service.NewTaskService(database)
-	if err := taskService.StoreTasks(tasks); err != nil {
-		logger.Error(fmt.Errorf("failed to store tasks: %w", err))
+	syncService := service.NewSyncService(database, service.NewProviderService(), taskService)
+	if cfg, err := config.Load(); err == nil && cfg.Sync.RemovalGracePeriod != nil {
+		syncService.SetRemovalGracePeriod(*cfg.Sync.RemovalGracePeriod)
+	}
+
+	// Fetch tasks from providers and store them
+	job, err := syncService.Run(service.TriggerCLI)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to sync tasks from providers: %w", err))
 		os.Exit(1)
 	}
 
+	printSyncJob(job)
+
 	// Get and display all tasks
 	storedTasks, err := taskService.GetTasks()
 	if err != nil {
@@ -67,7 +87,137 @@ func fetchTasks() {
 	fmt.Printf("\nFetched %d tasks:\n", len(storedTasks))
 	for _, task := range storedTasks {
 		fmt.Printf("ID: %d, Name: %s, Difficulty: %.2f, Duration: %.2f, Source: %s\n",
-			task.ID, *task.Name, task.Difficulty, task.EstimatedDuration, task.Source)
+			task.ID, task.DisplayName(), task.Difficulty, task.EstimatedDuration, task.Source)
+	}
+
+	if job.Status == model.SyncFailed {
+		os.Exit(1)
+	}
+}
+
+func importTasks(path, format, source, columns, durationUnit string, skipInvalid bool) {
+	if path == "" {
+		fmt.Println("import needs a --file")
+		os.Exit(1)
+	}
+
+	mapping, err := parseColumns(columns)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	client, err := provider.NewFileClient(provider.FileConfig{
+		Name:    source,
+		Path:    path,
+		Format:  format,
+		Columns: mapping,
+		Units:   provider.GenericUnits{Duration: durationUnit},
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	tasks, rowErrors, err := client.ReadTasks()
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to read tasks: %w", err))
+		os.Exit(1)
+	}
+
+	for _, rowError := range rowErrors {
+		fmt.Println(rowError.Error())
+	}
+
+	if len(rowErrors) > 0 && !skipInvalid {
+		fmt.Printf("\n%d of %d rows are invalid, nothing was imported. Fix them or use --skip-invalid.\n",
+			len(rowErrors), len(rowErrors)+len(tasks))
+		os.Exit(1)
+	}
+
+	database, err := db.NewConnection()
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to connect to database: %w", err))
+		os.Exit(1)
+	}
+
+	if err := db.AutoMigrate(database); err != nil {
+		logger.Error(fmt.Errorf("failed to run migrations: %w", err))
+		os.Exit(1)
+	}
+
+	taskService := service.NewTaskService(database)
+	if err := taskService.StoreTasks(tasks); err != nil {
+		logger.Error(fmt.Errorf("failed to store tasks: %w", err))
+		os.Exit(1)
+	}
+
+	fmt.Printf("\nImported %d tasks with source %s", len(tasks), source)
+	if len(rowErrors) > 0 {
+		fmt.Printf(", skipped %d invalid rows", len(rowErrors))
+	}
+	fmt.Println()
+}
+
+// parseColumns reads a column mapping like id=Key,duration=Hours
+func parseColumns(columns string) (provider.FileColumns, error) {
+	var mapping provider.FileColumns
+	if columns == "" {
+		return mapping, nil
+	}
+
+	for _, entry := range strings.Split(columns, ",") {
+		field, column, ok := strings.Cut(entry, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return mapping, fmt.Errorf("invalid column mapping %q, expected field=column", entry)
+		}
+
+		switch strings.ToLower(field) {
+		case "id":
+			mapping.ID = column
+		case "name":
+			mapping.Name = column
+		case "difficulty":
+			mapping.Difficulty = column
+		case "duration":
+			mapping.Duration = column
+		default:
+			return mapping, fmt.Errorf("unknown field %q in column mapping, expected id, name, difficulty or duration", field)
+		}
+	}
+
+	return mapping, nil
+}
+
+func printSyncJob(job *model.SyncJob) {
+	fmt.Printf("\nSync %s: fetched %d tasks, %d inserted, %d updated, %d unchanged, %d restored, %d removed\n",
+		job.Status, job.Fetched, job.Inserted, job.Updated, job.Unchanged, job.Restored, job.Removed)
+
+	for _, result := range job.Providers {
+		if result.Error != "" {
+			fmt.Printf("  %s: failed after %d attempts in %dms: %s\n", result.Provider, result.Attempts, result.LatencyMs, result.Error)
+			continue
+		}
+
+		if result.NotModified {
+			fmt.Printf("  %s: not modified since the last sync (%dms)\n", result.Provider, result.LatencyMs)
+			continue
+		}
+
+		if result.Incremental {
+			fmt.Printf("  %s: fetched %d changed tasks in %dms, %d inserted, %d updated, %d unchanged\n",
+				result.Provider, result.Fetched, result.LatencyMs, result.Inserted, result.Updated, result.Unchanged)
+			continue
+		}
+
+		fmt.Printf("  %s: fetched %d tasks in %dms, %d inserted, %d updated, %d unchanged, %d missing\n",
+			result.Provider, result.Fetched, result.LatencyMs, result.Inserted, result.Updated, result.Unchanged, result.Missing)
+
+		for _, removed := range result.Removed {
+			fmt.Printf("    removed %s (%s-%s), missing since %s\n",
+				removed.Name, removed.Source, removed.ExternalID, removed.MissingSince.Format(time.RFC3339))
+		}
 	}
 }
 
@@ -97,15 +247,52 @@ func initializeDatabase(force bool) {
 	}
 
 	if force {
+		// plan runs refer to developers and tasks, so they go first
+		if err := database.Exec("DELETE FROM assignment_pushes").Error; err != nil {
+			logger.Error(fmt.Errorf("failed to delete assignment pushes: %w", err))
+			os.Exit(1)
+		}
+
+		if err := database.Exec("DELETE FROM assignments").Error; err != nil {
+			logger.Error(fmt.Errorf("failed to delete assignments: %w", err))
+			os.Exit(1)
+		}
+
+		if err := database.Exec("DELETE FROM plan_runs").Error; err != nil {
+			logger.Error(fmt.Errorf("failed to delete plan runs: %w", err))
+			os.Exit(1)
+		}
+
+		if err := database.Exec("DELETE FROM developer_availabilities").Error; err != nil {
+			logger.Error(fmt.Errorf("failed to delete developer availability: %w", err))
+			os.Exit(1)
+		}
+
 		if err := database.Exec("DELETE FROM developers").Error; err != nil {
 			logger.Error(fmt.Errorf("failed to delete developers: %w", err))
 			os.Exit(1)
 		}
 
+		if err := database.Exec("DELETE FROM task_dependencies").Error; err != nil {
+			logger.Error(fmt.Errorf("failed to delete task dependencies: %w", err))
+			os.Exit(1)
+		}
+
+		if err := database.Exec("DELETE FROM task_revisions").Error; err != nil {
+			logger.Error(fmt.Errorf("failed to delete task revisions: %w", err))
+			os.Exit(1)
+		}
+
 		if err := database.Exec("DELETE FROM tasks").Error; err != nil {
 			logger.Error(fmt.Errorf("failed to delete tasks: %w", err))
 			os.Exit(1)
 		}
+
+		// without tasks the next sync has to fetch everything again
+		if err := database.Exec("DELETE FROM sync_cursors").Error; err != nil {
+			logger.Error(fmt.Errorf("failed to delete sync cursors: %w", err))
+			os.Exit(1)
+		}
 	}
 
 	// Create developers
@@ -5,13 +5,17 @@ import (
 	"os"
 	"time"
 
+	"todo-planning/internal/provider"
+
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Database       DatabaseConfig `yaml:"database"`
-	ProviderConfig ProviderConfig `yaml:"provider"`
+	Database       DatabaseConfig           `yaml:"database"`
+	ProviderConfig ProviderConfig           `yaml:"provider"`
+	Providers      []ProviderInstanceConfig `yaml:"providers"` // replaces the provider section when set
+	Sync           SyncConfig               `yaml:"sync"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -33,8 +37,43 @@ type DatabaseConfig struct {
 }
 
 type ProviderConfig struct {
-	MockOne MockOneConfig `yaml:"mock-one"`
-	MockTwo MockTwoConfig `yaml:"mock-two"`
+	MockOne MockOneConfig            `yaml:"mock-one"`
+	MockTwo MockTwoConfig            `yaml:"mock-two"`
+	Generic []provider.GenericConfig `yaml:"generic"` // JSON feeds mapped onto tasks without code
+}
+
+// ProviderInstanceConfig configures one instance of a registered provider type
+type ProviderInstanceConfig struct {
+	Type    string         `yaml:"type"`
+	Name    string         `yaml:"name"` // unique, used as the task source; the type when empty
+	Enabled *bool          `yaml:"enabled"`
+	Options map[string]any `yaml:"options"`
+
+	Timeout    time.Duration `yaml:"timeout"`     // limit of a single fetch attempt, 30s by default
+	Retries    int           `yaml:"retries"`     // attempts after a failed fetch
+	RetryDelay time.Duration `yaml:"retry_delay"` // pause between attempts, 1s by default
+
+	Webhook WebhookConfig `yaml:"webhook"`
+}
+
+// WebhookConfig lets the source of a provider push changes to
+// POST /api/webhooks/:source
+type WebhookConfig struct {
+	Secret    provider.Secret `yaml:"secret"`    // key of the HMAC-SHA256 signature, webhooks are refused without one
+	Header    string          `yaml:"header"`    // header carrying the hex signature, X-Signature-256 by default
+	Tolerance time.Duration   `yaml:"tolerance"` // how far the signed timestamp may be off, 5m by default
+	Replan    bool            `yaml:"replan"`    // create a new plan after applying a change
+}
+
+// Enabled reports whether the source may push changes
+func (w WebhookConfig) Enabled() bool {
+	return w.Secret != (provider.Secret{})
+}
+
+// IsEnabled reports whether the instance should be used, instances are
+// enabled unless turned off explicitly
+func (p ProviderInstanceConfig) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
 }
 
 type MockOneConfig struct {
@@ -45,6 +84,17 @@ type MockTwoConfig struct {
 	Url string `yaml:"url"`
 }
 
+// SyncConfig holds the schedule of the provider sync run by the API server
+type SyncConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Schedule string        `yaml:"schedule"` // a duration such as "30m" or a cron expression
+	Jitter   time.Duration `yaml:"jitter"`   // random delay added to every run
+
+	// how long a task may be missing from its provider before it is deleted,
+	// 24h when not set and "0s" to delete it with the first sync that misses it
+	RemovalGracePeriod *time.Duration `yaml:"removal_grace_period"`
+}
+
 var config *Config
 
 // Load reads the configuration file and returns a Config struct
@@ -17,6 +17,13 @@ func AutoMigrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&model.Task{},
 		&model.Developer{},
+		&model.DeveloperAvailability{},
 		&model.Assignment{},
+		&model.PlanRun{},
+		&model.AssignmentPush{},
+		&model.TaskDependency{},
+		&model.TaskRevision{},
+		&model.SyncJob{},
+		&model.SyncCursor{},
 	)
 }
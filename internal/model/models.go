@@ -1,36 +1,115 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// SourceManual is the source of tasks entered through the API instead of a provider
+const SourceManual = "manual"
+
 type Task struct {
 	ID                uint           `gorm:"primaryKey" json:"id"`
 	ExternalID        string         `gorm:"uniqueIndex:idx_source_external_id" json:"external_id"`
 	Name              *string        `json:"name"`
 	Difficulty        float64        `json:"difficulty"`
 	EstimatedDuration float64        `json:"estimated_duration"`
+	Priority          int            `json:"priority"`           // higher is more important, set through the API
+	Deadline          *time.Time     `json:"deadline,omitempty"` // set through the API
 	Source            string         `gorm:"uniqueIndex:idx_source_external_id" json:"source"`
+	ParentID          *uint          `gorm:"index" json:"parent_id,omitempty"`
+	Sequence          int            `json:"sequence,omitempty"`         // position among the sub-tasks of the parent
+	MissingSince      *time.Time     `json:"missing_since,omitempty"`    // first sync the provider no longer returned the task
+	RemovedUpstream   bool           `json:"removed_upstream,omitempty"` // deleted because the provider no longer returns it
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 	Assignment        *Assignment    `gorm:"foreignKey:TaskID" json:"assignment,omitempty"`
+	SubTasks          []Task         `gorm:"foreignKey:ParentID" json:"sub_tasks,omitempty"`
+	DependsOn         []uint         `gorm:"-" json:"depends_on,omitempty"` // tasks to finish first, filled in by the planner
+}
+
+// DisplayName returns the task name, or a name built from its source and
+// external id when the provider didn't send one
+func (t Task) DisplayName() string {
+	if t.Name != nil {
+		return *t.Name
+	}
+
+	return fmt.Sprintf("Task %s - %s", t.Source, t.ExternalID)
+}
+
+// Part returns the given part of the task split into a number of parts
+// sharing its duration equally, without an id
+func (t Task) Part(sequence, parts int) Task {
+	name := fmt.Sprintf("%s (part %d/%d)", t.DisplayName(), sequence, parts)
+	parentID := t.ID
+
+	return Task{
+		ExternalID:        fmt.Sprintf("%s#%d", t.ExternalID, sequence),
+		Name:              &name,
+		Difficulty:        t.Difficulty,
+		EstimatedDuration: t.EstimatedDuration / float64(parts),
+		Source:            t.Source,
+		ParentID:          &parentID,
+		Sequence:          sequence,
+	}
+}
+
+// TaskRevision records the fields of a task changed by a provider sync or
+// through the API
+type TaskRevision struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	TaskID    uint         `gorm:"index" json:"task_id"`
+	ChangedBy string       `json:"changed_by"` // the provider source or "api"
+	Changes   []TaskChange `gorm:"type:text;serializer:json" json:"changes"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// TaskChange is a single changed field of a task revision
+type TaskChange struct {
+	Field    string `json:"field"`
+	OldValue any    `json:"old_value"`
+	NewValue any    `json:"new_value"`
+}
+
+// TaskDependency states that a task can't start before another one is finished
+type TaskDependency struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	TaskID      uint      `gorm:"uniqueIndex:idx_task_depends_on" json:"task_id"`
+	DependsOnID uint      `gorm:"uniqueIndex:idx_task_depends_on;index" json:"depends_on_id"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Developer struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	Name         string         `json:"name"`
-	Productivity float64        `json:"productivity"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at"`
-	Assignments  []Assignment   `gorm:"foreignKey:DeveloperID" json:"assignments,omitempty"`
+	ID             uint                    `gorm:"primaryKey" json:"id"`
+	Name           string                  `json:"name"`
+	Productivity   float64                 `json:"productivity"`
+	WeeklyCapacity *float64                `gorm:"default:45" json:"weekly_capacity"` // hours per week, 0 for developers who get no planned work
+	CreatedAt      time.Time               `json:"created_at"`
+	UpdatedAt      time.Time               `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt          `gorm:"index" json:"deleted_at"`
+	Assignments    []Assignment            `gorm:"foreignKey:DeveloperID" json:"assignments,omitempty"`
+	Availability   []DeveloperAvailability `gorm:"foreignKey:DeveloperID" json:"availability,omitempty"`
+}
+
+// DeveloperAvailability overrides the weekly capacity of a developer for a
+// single plan week, e.g. for holidays, part-time weeks or on-call duty
+type DeveloperAvailability struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	DeveloperID uint      `gorm:"uniqueIndex:idx_developer_week" json:"developer_id"`
+	WeekNumber  int       `gorm:"uniqueIndex:idx_developer_week" json:"week_number"`
+	Hours       float64   `json:"hours"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type Assignment struct {
 	ID              uint           `gorm:"primaryKey" json:"id"`
+	PlanRunID       *uint          `gorm:"index" json:"plan_run_id,omitempty"`
 	DeveloperID     uint           `json:"developer_id"`
 	TaskID          uint           `json:"task_id"`
 	WeekNumber      int            `json:"week_number"`
@@ -42,6 +121,139 @@ type Assignment struct {
 	Task            Task           `gorm:"foreignKey:TaskID" json:"task"`
 }
 
+// PlanRun is a stored result of a single planner run together with the
+// tasks and developers it was computed from
+type PlanRun struct {
+	ID                uint             `gorm:"primaryKey" json:"id"`
+	Version           int              `gorm:"index" json:"version"`
+	Sorter            string           `json:"sorter"`
+	SorterSeed        *uint64          `json:"sorter_seed,omitempty"` // seed of the random sorter, to plan the same order again
+	Solver            string           `json:"solver"`
+	Optimal           bool             `json:"optimal"`
+	Dependencies      string           `json:"dependencies"`
+	TaskCount         int              `json:"task_count"`
+	DeveloperCount    int              `json:"developer_count"`
+	TotalHours        float64          `json:"total_hours"`
+	TotalWeeks        int              `json:"total_weeks"`
+	Splits            []TaskSplit      `gorm:"type:text;serializer:json" json:"splits,omitempty"`
+	Unassigned        []UnassignedTask `gorm:"type:text;serializer:json" json:"unassigned,omitempty"`
+	TaskSnapshot      []Task           `gorm:"type:text;serializer:json" json:"task_snapshot,omitempty"`
+	DeveloperSnapshot []Developer      `gorm:"type:text;serializer:json" json:"developer_snapshot,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt   `gorm:"index" json:"deleted_at"`
+	Assignments       []Assignment     `gorm:"foreignKey:PlanRunID" json:"assignments,omitempty"`
+}
+
+// Assignment push statuses
+const (
+	PushSucceeded = "pushed"
+	PushDryRun    = "dry_run"
+	PushSkipped   = "skipped" // the source of the task can't take assignments
+	PushFailed    = "failed"
+)
+
+// AssignmentPush records writing the planned developer and week of a task
+// back to the source of the task
+type AssignmentPush struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PlanRunID  uint      `gorm:"index" json:"plan_run_id"`
+	TaskID     uint      `json:"task_id"`
+	Source     string    `json:"source"`
+	ExternalID string    `json:"external_id"`
+	Developer  string    `json:"developer"`
+	WeekNumber int       `json:"week_number"`
+	DryRun     bool      `json:"dry_run"`
+	Status     string    `json:"status"`
+	Message    string    `json:"message"` // what was or would be changed, or why nothing was
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TaskSplit describes a task that was split into ordered sub-tasks because
+// it doesn't fit into a single week of any developer
+type TaskSplit struct {
+	TaskID     uint   `json:"task_id"`
+	TaskName   string `json:"task_name"`
+	Parts      int    `json:"parts"`
+	SubTaskIDs []uint `json:"sub_task_ids"`
+}
+
+// UnassignedTask is a task the planner couldn't give to any developer
+type UnassignedTask struct {
+	Task            Task    `json:"task"`
+	Reason          string  `json:"reason"`
+	MinProductivity float64 `json:"min_productivity"` // productivity needed to finish the task within a week
+}
+
+// Sync job statuses
+const (
+	SyncPending   = "pending"
+	SyncRunning   = "running"
+	SyncSucceeded = "succeeded"
+	SyncPartial   = "partial" // some providers failed
+	SyncFailed    = "failed"
+)
+
+// SyncCursor is where the incremental fetch of a source continues from
+type SyncCursor struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Source       string     `gorm:"uniqueIndex;not null" json:"source"`
+	ETag         string     `gorm:"column:etag" json:"etag,omitempty"`
+	LastModified string     `json:"last_modified,omitempty"`
+	UpdatedSince *time.Time `json:"updated_since,omitempty"` // start of the last successful fetch
+	FullSyncAt   *time.Time `json:"full_sync_at,omitempty"`  // start of the last fetch of all tasks
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// SyncJob is a run of fetching tasks from the providers and storing them
+type SyncJob struct {
+	ID         uint                 `gorm:"primaryKey" json:"id"`
+	Status     string               `gorm:"index" json:"status"`
+	Trigger    string               `json:"trigger"`
+	Fetched    int                  `json:"fetched"`
+	Stored     int                  `json:"stored"`
+	Inserted   int                  `json:"inserted"`
+	Updated    int                  `json:"updated"`
+	Unchanged  int                  `json:"unchanged"`
+	Restored   int                  `json:"restored"`
+	Removed    int                  `json:"removed"`
+	Providers  []ProviderSyncResult `gorm:"type:text;serializer:json" json:"providers"`
+	Error      string               `json:"error,omitempty"`
+	StartedAt  *time.Time           `json:"started_at,omitempty"`
+	FinishedAt *time.Time           `json:"finished_at,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
+
+// ProviderSyncResult is the outcome of a sync job for a single provider
+type ProviderSyncResult struct {
+	Provider    string        `json:"provider"`
+	Success     bool          `json:"success"`
+	NotModified bool          `json:"not_modified,omitempty"` // nothing changed since the last sync, nothing was stored
+	Incremental bool          `json:"incremental,omitempty"`  // only changed tasks were fetched, nothing was removed
+	LatencyMs   int64         `json:"latency_ms"`             // time spent fetching, retries included
+	Attempts    int           `json:"attempts"`
+	Fetched     int           `json:"fetched"`
+	Stored      int           `json:"stored"`
+	Inserted    int           `json:"inserted"`
+	Updated     int           `json:"updated"`
+	Unchanged   int           `json:"unchanged"`
+	Restored    int           `json:"restored"`
+	Missing     int           `json:"missing"` // tasks no longer returned, removed after the grace period
+	Removed     []RemovedTask `json:"removed,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// RemovedTask is a task deleted because its provider no longer returns it
+type RemovedTask struct {
+	TaskID       uint      `json:"task_id"`
+	Source       string    `json:"source"`
+	ExternalID   string    `json:"external_id"`
+	Name         string    `json:"name"`
+	MissingSince time.Time `json:"missing_since"`
+}
+
 type AssignmentResponse struct {
 	WeekNumber      int       `json:"week_number"`
 	TaskName        string    `json:"task_name"`
@@ -5,12 +5,84 @@ import (
 	"todo-planning/internal/model"
 )
 
-// max hours a developer can work in a week
+// max hours a developer can work in a week unless the developer has a capacity of their own
 const MaxHoursPerWeek = 45
 
 type devState struct {
-	Developer model.Developer
-	WeekLoads map[int]float64 // week -> hours
+	Developer    model.Developer
+	WeekLoads    map[int]float64 // week -> hours
+	Availability map[int]float64 // week -> hours available, overrides the weekly capacity
+}
+
+func newDevState(developer model.Developer) *devState {
+	availability := make(map[int]float64, len(developer.Availability))
+	for _, a := range developer.Availability {
+		availability[a.WeekNumber] = a.Hours
+	}
+
+	return &devState{
+		Developer:    developer,
+		WeekLoads:    make(map[int]float64, 10),
+		Availability: availability,
+	}
+}
+
+// Capacity returns the hours the developer can work in the given week
+func (ds *devState) Capacity(week int) float64 {
+	if hours, ok := ds.Availability[week]; ok {
+		return hours
+	}
+
+	return WeeklyCapacity(ds.Developer)
+}
+
+// FirstFit returns the first week starting from the given one that has room
+// for the hours. It returns false when no week ever will.
+func (ds *devState) FirstFit(week int, hours float64) (int, bool) {
+	lastOverride := 0
+	for w := range ds.Availability {
+		if w > lastOverride {
+			lastOverride = w
+		}
+	}
+
+	// after the last override every week has the regular capacity
+	if hours > WeeklyCapacity(ds.Developer) && week > lastOverride {
+		return 0, false
+	}
+
+	for {
+		if ds.WeekLoads[week]+hours <= ds.Capacity(week) {
+			return week, true
+		}
+
+		week++
+		if week > lastOverride && hours > WeeklyCapacity(ds.Developer) {
+			return 0, false
+		}
+	}
+}
+
+// MaxCapacity returns the most hours the developer can work in any week
+func (ds *devState) MaxCapacity() float64 {
+	capacity := WeeklyCapacity(ds.Developer)
+	for _, hours := range ds.Availability {
+		if hours > capacity {
+			capacity = hours
+		}
+	}
+
+	return capacity
+}
+
+// WeeklyCapacity returns the regular weekly hours of a developer, a
+// developer without a capacity of their own works a regular week
+func WeeklyCapacity(developer model.Developer) float64 {
+	if developer.WeeklyCapacity != nil {
+		return *developer.WeeklyCapacity
+	}
+
+	return MaxHoursPerWeek
 }
 
 // ChannelManager defines the interface for managing channel operations
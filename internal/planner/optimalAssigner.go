@@ -0,0 +1,350 @@
+package planner
+
+import (
+	"math"
+	"sort"
+	"time"
+	"todo-planning/internal/logger"
+	"todo-planning/internal/model"
+)
+
+// DefaultTimeBudget is the time the optimal solver may spend searching
+// before it falls back to the best plan found so far
+const DefaultTimeBudget = 2 * time.Second
+
+// capacityEpsilon absorbs floating point noise when comparing hours
+const capacityEpsilon = 1e-9
+
+// OptimalAssigner assigns tasks with a branch-and-bound search over
+// developers × weeks that minimizes the number of weeks (makespan) needed
+// to finish all tasks within the weekly capacity of every developer.
+//
+// The greedy TaskAssigner result is used as the initial incumbent. The
+// search then tries to fit every task into fewer weeks, starting from a
+// capacity based lower bound, until it proves the incumbent optimal or the
+// time budget runs out. Within the fewest weeks the tasks keep the order of
+// the sorter: earlier tasks are placed in the earliest week they can go.
+type OptimalAssigner struct {
+	developers []model.Developer
+	timeBudget time.Duration
+}
+
+func NewOptimalAssigner(developers []model.Developer, timeBudget time.Duration) *OptimalAssigner {
+	if timeBudget <= 0 {
+		timeBudget = DefaultTimeBudget
+	}
+
+	return &OptimalAssigner{
+		developers: developers,
+		timeBudget: timeBudget,
+	}
+}
+
+// Assign returns the assignments for the tasks and whether they are proven
+// to use the minimum number of weeks. Tasks that don't fit into a single
+// week of any developer are left out.
+func (oa *OptimalAssigner) Assign(tasks []model.Task) ([]model.Assignment, bool) {
+	deadline := time.Now().Add(oa.timeBudget)
+
+	incumbent := oa.greedy(tasks)
+	makespan := totalWeeks(incumbent)
+	if len(incumbent) == 0 {
+		return incumbent, true
+	}
+
+	// the incumbent holds the tasks that fit in the order of the sorter
+	tasks = make([]model.Task, 0, len(incumbent))
+	for _, assignment := range incumbent {
+		tasks = append(tasks, assignment.Task)
+	}
+
+	search := newBinSearch(oa.developers, byEffort(tasks), deadline)
+	for weeks := search.lowerBound(); weeks < makespan; weeks++ {
+		placements, found, timedOut := search.solve(weeks)
+		if timedOut {
+			logger.Info("optimal solver ran out of time, using best plan with ", makespan, " weeks")
+			return incumbent, false
+		}
+
+		if !found {
+			continue
+		}
+
+		// the weeks are known to suffice, now the earlier tasks of the sorter get the earlier weeks
+		ordered := newBinSearch(oa.developers, orderByDependencies(tasks), deadline)
+		if orderedPlacements, found, _ := ordered.solve(weeks); found {
+			return ordered.assignments(orderedPlacements), true
+		}
+
+		logger.Info("optimal solver ran out of time ordering the tasks, using a plan with ", weeks, " weeks in effort order")
+		return search.assignments(placements), true
+	}
+
+	return incumbent, true
+}
+
+// byEffort orders the tasks largest effort first after their dependencies.
+// Placing large tasks first fails early and prunes most of the search tree.
+func byEffort(tasks []model.Task) []model.Task {
+	sorted := make([]model.Task, len(tasks))
+	copy(sorted, tasks)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return CalculateTaskEffort(sorted[i]) > CalculateTaskEffort(sorted[j])
+	})
+
+	return orderByDependencies(sorted)
+}
+
+// greedy builds the initial incumbent with the default task assigner
+func (oa *OptimalAssigner) greedy(tasks []model.Task) []model.Assignment {
+	taskAssigner := NewTaskAssigner(oa.developers)
+
+	assignments := make([]model.Assignment, 0, len(tasks))
+	for _, task := range tasks {
+		if assignment := taskAssigner.AssignTask(task); assignment != nil {
+			assignments = append(assignments, *assignment)
+		} else {
+			logger.Info("Assignment can't be made to any Developer for task: ", task.Source+"-"+task.ExternalID, " consider splitting it into 2 issues")
+		}
+	}
+
+	return assignments
+}
+
+func totalWeeks(assignments []model.Assignment) int {
+	weeks := 0
+	for _, assignment := range assignments {
+		if assignment.WeekNumber > weeks {
+			weeks = assignment.WeekNumber
+		}
+	}
+
+	return weeks
+}
+
+// binSearch is a feasibility search that packs tasks into developer weeks.
+// Tasks with dependencies are always placed in a later week than the tasks
+// they depend on, which keeps developers with the same productivity
+// interchangeable.
+type binSearch struct {
+	developers    []model.Developer
+	states        []*devState  // capacities of the developers
+	maxCapacity   []float64    // most hours of any week per developer
+	tasks         []model.Task // the tasks to place in order, after their dependencies
+	efforts       []float64
+	prerequisites [][]int // indexes of the tasks that have to be placed in an earlier week
+	constrained   bool
+	deadline      time.Time
+	nodes         int
+
+	// state of the current search
+	weeks     int
+	remaining [][]float64 // developer -> week -> hours left
+	placement []binPlacement
+}
+
+type binPlacement struct {
+	developer int
+	week      int
+	hours     float64
+}
+
+type binKey struct {
+	productivity float64
+	remaining    float64
+	week         int
+}
+
+// newBinSearch prepares a search placing the tasks in the given order, every
+// task after the tasks it depends on
+func newBinSearch(developers []model.Developer, tasks []model.Task, deadline time.Time) *binSearch {
+	index := make(map[uint]int, len(tasks))
+	efforts := make([]float64, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+		efforts[i] = CalculateTaskEffort(task)
+	}
+
+	prerequisites := make([][]int, len(tasks))
+	constrained := false
+	for i, task := range tasks {
+		for _, id := range task.DependsOn {
+			if j, ok := index[id]; ok && j < i {
+				prerequisites[i] = append(prerequisites[i], j)
+				constrained = true
+			}
+		}
+	}
+
+	states := make([]*devState, 0, len(developers))
+	maxCapacity := make([]float64, 0, len(developers))
+	for _, developer := range developers {
+		state := newDevState(developer)
+		states = append(states, state)
+		maxCapacity = append(maxCapacity, state.MaxCapacity())
+	}
+
+	return &binSearch{
+		developers:    developers,
+		states:        states,
+		maxCapacity:   maxCapacity,
+		tasks:         tasks,
+		efforts:       efforts,
+		prerequisites: prerequisites,
+		constrained:   constrained,
+		deadline:      deadline,
+	}
+}
+
+// lowerBound is the number of weeks the whole team needs to get through the
+// total effort when every developer is fully loaded with their best week
+func (bs *binSearch) lowerBound() int {
+	var totalEffort, weeklyEffort float64
+	for _, effort := range bs.efforts {
+		totalEffort += effort
+	}
+
+	for d, developer := range bs.developers {
+		if developer.Productivity > 0 {
+			weeklyEffort += developer.Productivity * bs.maxCapacity[d]
+		}
+	}
+
+	bound := 1
+	if weeklyEffort > 0 {
+		bound = int(math.Max(1, math.Ceil(totalEffort/weeklyEffort-capacityEpsilon)))
+	}
+
+	// a chain of dependent tasks needs one week per task
+	chains := make([]int, len(bs.tasks))
+	for i := range bs.tasks {
+		chains[i] = 1
+		for _, j := range bs.prerequisites[i] {
+			if chains[j]+1 > chains[i] {
+				chains[i] = chains[j] + 1
+			}
+		}
+
+		if chains[i] > bound {
+			bound = chains[i]
+		}
+	}
+
+	return bound
+}
+
+// solve looks for a placement of all tasks within the given number of weeks
+func (bs *binSearch) solve(weeks int) ([]binPlacement, bool, bool) {
+	bs.weeks = weeks
+	bs.remaining = make([][]float64, len(bs.developers))
+	var capacity float64
+	for d := range bs.developers {
+		bs.remaining[d] = make([]float64, weeks)
+		for w := range bs.remaining[d] {
+			bs.remaining[d][w] = bs.states[d].Capacity(w + 1)
+
+			if bs.developers[d].Productivity > 0 {
+				capacity += bs.developers[d].Productivity * bs.remaining[d][w]
+			}
+		}
+	}
+
+	var totalEffort float64
+	for _, effort := range bs.efforts {
+		totalEffort += effort
+	}
+
+	bs.placement = make([]binPlacement, len(bs.tasks))
+
+	found, timedOut := bs.place(0, totalEffort, capacity)
+	if !found {
+		return nil, false, timedOut
+	}
+
+	return bs.placement, true, false
+}
+
+// place tries to put task i and every task after it into a developer week.
+// effortLeft is the effort of the unplaced tasks, capacityLeft the effort the
+// team can still absorb in the remaining hours.
+func (bs *binSearch) place(i int, effortLeft, capacityLeft float64) (bool, bool) {
+	if i == len(bs.tasks) {
+		return true, false
+	}
+
+	bs.nodes++
+	if bs.nodes%1024 == 0 && time.Now().After(bs.deadline) {
+		return false, true
+	}
+
+	if effortLeft > capacityLeft+capacityEpsilon {
+		return false, false
+	}
+
+	// developer weeks with the same productivity and the same hours left lead
+	// to identical subproblems, so only the first of them is explored. Once
+	// tasks are ordered across weeks the week itself matters as well.
+	tried := make(map[binKey]struct{})
+
+	first := 0
+	for _, j := range bs.prerequisites[i] {
+		if bs.placement[j].week > first {
+			first = bs.placement[j].week
+		}
+	}
+
+	// earlier weeks are tried first, so the first placement found puts every
+	// task as early as the tasks before it allow
+	for w := first; w < bs.weeks; w++ {
+		for d, developer := range bs.developers {
+			hours := CalculateHoursNeeded(bs.efforts[i], developer)
+			if hours > bs.maxCapacity[d] {
+				continue
+			}
+
+			remaining := bs.remaining[d][w]
+			if remaining+capacityEpsilon < hours {
+				continue
+			}
+
+			key := binKey{productivity: developer.Productivity, remaining: math.Round(remaining*1e6) / 1e6}
+			if bs.constrained {
+				key.week = w
+			}
+			if _, ok := tried[key]; ok {
+				continue
+			}
+			tried[key] = struct{}{}
+
+			bs.remaining[d][w] -= hours
+			bs.placement[i] = binPlacement{developer: d, week: w + 1, hours: hours}
+
+			found, timedOut := bs.place(i+1, effortLeft-bs.efforts[i], capacityLeft-hours*developer.Productivity)
+			if found || timedOut {
+				return found, timedOut
+			}
+
+			bs.remaining[d][w] += hours
+		}
+	}
+
+	return false, false
+}
+
+func (bs *binSearch) assignments(placements []binPlacement) []model.Assignment {
+	assignments := make([]model.Assignment, 0, len(placements))
+	for i, p := range placements {
+		developer := bs.developers[p.developer]
+		assignments = append(assignments, model.Assignment{
+			TaskID:          bs.tasks[i].ID,
+			DeveloperID:     developer.ID,
+			WeekNumber:      p.week,
+			CalculatedHours: p.hours,
+			Task:            bs.tasks[i],
+			Developer:       developer,
+		})
+	}
+
+	return assignments
+}
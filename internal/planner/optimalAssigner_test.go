@@ -0,0 +1,156 @@
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"todo-planning/internal/model"
+	"todo-planning/internal/utility"
+)
+
+func TestOptimalAssigner_Assign(t *testing.T) {
+	developers := []model.Developer{
+		{ID: 1, Productivity: 1},
+		{ID: 2, Productivity: 2},
+	}
+
+	tests := []struct {
+		name          string
+		tasks         []model.Task
+		expectedCount int
+		expectedWeeks int
+	}{
+		{
+			name: "beats greedy plan",
+			tasks: []model.Task{
+				{ID: 1, Difficulty: 1, EstimatedDuration: 36},
+				{ID: 2, Difficulty: 1, EstimatedDuration: 20},
+				{ID: 3, Difficulty: 1, EstimatedDuration: 11},
+				{ID: 4, Difficulty: 1, EstimatedDuration: 14},
+				{ID: 5, Difficulty: 1, EstimatedDuration: 14},
+				{ID: 6, Difficulty: 1, EstimatedDuration: 15},
+				{ID: 7, Difficulty: 1, EstimatedDuration: 23},
+			},
+			expectedCount: 7,
+			expectedWeeks: 1,
+		},
+		{
+			name: "needs more than one week",
+			tasks: []model.Task{
+				{ID: 1, Difficulty: 2, EstimatedDuration: 40},
+				{ID: 2, Difficulty: 2, EstimatedDuration: 40},
+				{ID: 3, Difficulty: 1, EstimatedDuration: 40},
+			},
+			expectedCount: 3,
+			expectedWeeks: 2,
+		},
+		{
+			name: "task too large for every developer is left out",
+			tasks: []model.Task{
+				{ID: 1, Difficulty: 10, EstimatedDuration: 10},
+				{ID: 2, Difficulty: 1, EstimatedDuration: 10},
+			},
+			expectedCount: 1,
+			expectedWeeks: 1,
+		},
+		{
+			name:          "no tasks",
+			tasks:         []model.Task{},
+			expectedCount: 0,
+			expectedWeeks: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortedTasks := (&DefaultTaskSorter{}).Sort(tt.tasks)
+			assignments, optimal := NewOptimalAssigner(developers, time.Second).Assign(sortedTasks)
+
+			if !optimal {
+				t.Error("expected plan to be proven optimal")
+			}
+			if len(assignments) != tt.expectedCount {
+				t.Fatalf("expected %d assignments, got %d", tt.expectedCount, len(assignments))
+			}
+			if weeks := totalWeeks(assignments); weeks != tt.expectedWeeks {
+				t.Errorf("expected %d weeks, got %d", tt.expectedWeeks, weeks)
+			}
+
+			loads := make(map[uint]map[int]float64)
+			for _, assignment := range assignments {
+				if loads[assignment.DeveloperID] == nil {
+					loads[assignment.DeveloperID] = make(map[int]float64)
+				}
+				loads[assignment.DeveloperID][assignment.WeekNumber] += assignment.CalculatedHours
+			}
+			for devID, weeks := range loads {
+				for week, hours := range weeks {
+					if hours > MaxHoursPerWeek+capacityEpsilon {
+						t.Errorf("developer %d has %f hours in week %d", devID, hours, week)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOptimalAssigner_TimeBudget(t *testing.T) {
+	developers := []model.Developer{
+		{ID: 1, Productivity: 1},
+		{ID: 2, Productivity: 1},
+		{ID: 3, Productivity: 3},
+	}
+
+	var tasks []model.Task
+	for i := 1; i <= 60; i++ {
+		tasks = append(tasks, model.Task{ID: uint(i), Difficulty: float64(i%5 + 1), EstimatedDuration: float64(i%7 + 3)})
+	}
+
+	sortedTasks := (&DefaultTaskSorter{}).Sort(tasks)
+	greedyWeeks := totalWeeks(NewOptimalAssigner(developers, 0).greedy(sortedTasks))
+
+	start := time.Now()
+	assignments, _ := NewOptimalAssigner(developers, time.Millisecond).Assign(sortedTasks)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected solver to respect the time budget, took %s", elapsed)
+	}
+
+	if len(assignments) != len(tasks) {
+		t.Errorf("expected %d assignments, got %d", len(tasks), len(assignments))
+	}
+	if weeks := totalWeeks(assignments); weeks > greedyWeeks {
+		t.Errorf("expected at most %d weeks, got %d", greedyWeeks, weeks)
+	}
+}
+
+func TestOptimalAssigner_AssignAvailability(t *testing.T) {
+	developers := []model.Developer{
+		{ID: 1, Productivity: 1, WeeklyCapacity: utility.ToPointer(20.0)},
+		{
+			ID:           2,
+			Productivity: 1,
+			Availability: []model.DeveloperAvailability{
+				{DeveloperID: 2, WeekNumber: 1, Hours: 0, Reason: "vacation"},
+			},
+		},
+	}
+
+	tasks := []model.Task{
+		{ID: 1, Difficulty: 1, EstimatedDuration: 20},
+		{ID: 2, Difficulty: 1, EstimatedDuration: 40},
+	}
+
+	assignments, _ := NewOptimalAssigner(developers, time.Second).Assign(tasks)
+	if len(assignments) != 2 {
+		t.Fatalf("expected 2 assignments, got %d", len(assignments))
+	}
+
+	for _, assignment := range assignments {
+		if assignment.TaskID == 2 && (assignment.DeveloperID != 2 || assignment.WeekNumber != 2) {
+			t.Errorf("expected task 2 for developer 2 in week 2, got developer %d week %d", assignment.DeveloperID, assignment.WeekNumber)
+		}
+		if assignment.TaskID == 1 && (assignment.DeveloperID != 1 || assignment.WeekNumber != 1) {
+			t.Errorf("expected task 1 for developer 1 in week 1, got developer %d week %d", assignment.DeveloperID, assignment.WeekNumber)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package planner
 
 import (
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"sync"
 	"time"
 	"todo-planning/internal/logger"
@@ -13,6 +15,9 @@ import (
 // Service interfaces for dependency injection
 type TaskService interface {
 	GetTasks() ([]model.Task, error)
+	SplitTask(task model.Task, parts int) ([]model.Task, error)
+	UnsplitTask(task model.Task) error
+	GetDependencies() ([]model.TaskDependency, error)
 }
 
 type DeveloperService interface {
@@ -20,7 +25,7 @@ type DeveloperService interface {
 }
 
 type AssignmentService interface {
-	// Add methods as needed
+	CreatePlanRun(run *model.PlanRun) error
 }
 
 type Planner struct {
@@ -29,6 +34,7 @@ type Planner struct {
 	assignmentService AssignmentService
 	taskSorter        TaskSorter
 	channelManager    ChannelManager
+	saveAssignments   bool
 	mu                sync.Mutex
 }
 
@@ -42,6 +48,37 @@ type PlanningOptions struct {
 	ChannelManager    ChannelManager
 }
 
+// Solvers that can be selected for a plan run
+const (
+	SolverGreedy  = "greedy"
+	SolverOptimal = "optimal"
+)
+
+var (
+	ErrUnknownSolver         = errors.New("unknown solver")
+	ErrUnknownDependencyMode = errors.New("unknown dependency mode")
+)
+
+// PlanRequest holds the options of a single plan run
+type PlanRequest struct {
+	// Solver is either SolverGreedy (default) or SolverOptimal
+	Solver string
+	// TimeBudget limits the optimal solver, DefaultTimeBudget is used when zero
+	TimeBudget time.Duration
+	// DisableSplitting keeps tasks that don't fit into a week as they are
+	// instead of splitting them into sub-tasks
+	DisableSplitting bool
+	// Dependencies is either DependenciesStrict (default) or DependenciesIgnore
+	Dependencies string
+	// Sorter names a registered sorter, the sorter of the planner is used when empty
+	Sorter string
+	// Seed is passed to seeded sorters, a random one is picked when nil
+	Seed *uint64
+	// Preview plans without storing anything, neither the plan run nor the
+	// sub-tasks of split tasks. The sub-tasks of a preview have no id.
+	Preview bool
+}
+
 var planner *Planner
 
 func newPlanner(options PlanningOptions) *Planner {
@@ -61,6 +98,7 @@ func newPlanner(options PlanningOptions) *Planner {
 		assignmentService: options.AssignmentService,
 		taskSorter:        taskSorter,
 		channelManager:    channelManager,
+		saveAssignments:   options.SaveAssignments,
 	}
 }
 
@@ -77,18 +115,70 @@ func (p *Planner) RunRoutines() {
 }
 
 func (p *Planner) Plan() ([]model.Assignment, error) {
+	run, err := p.CreatePlan(PlanRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return run.Assignments, nil
+}
+
+// CreatePlan runs the planner and returns the result as a plan run.
+// The run is stored through the assignment service when SaveAssignments is
+// set and the request isn't a preview
+func (p *Planner) CreatePlan(request PlanRequest) (*model.PlanRun, error) {
+	switch request.Solver {
+	case "":
+		request.Solver = SolverGreedy
+	case SolverGreedy, SolverOptimal:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSolver, request.Solver)
+	}
+
+	switch request.Dependencies {
+	case "":
+		request.Dependencies = DependenciesStrict
+	case DependenciesStrict, DependenciesIgnore:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDependencyMode, request.Dependencies)
+	}
+
+	taskSorter := p.taskSorter
+	if request.Sorter != "" {
+		seed := rand.Uint64()
+		if request.Seed != nil {
+			seed = *request.Seed
+		}
+
+		var err error
+		if taskSorter, err = NewSorter(request.Sorter, seed); err != nil {
+			return nil, err
+		}
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	p.RunRoutines()
 
-	assignments, err := p.plan()
+	run, err := p.plan(request, taskSorter)
 	p.Stop()
 
-	return assignments, err
+	if err != nil {
+		return nil, err
+	}
+
+	if p.saveAssignments && p.assignmentService != nil && !request.Preview {
+		if err := p.assignmentService.CreatePlanRun(run); err != nil {
+			logger.Error(err)
+			return nil, fmt.Errorf("failed to save plan run: %w", err)
+		}
+	}
+
+	return run, nil
 }
 
-func (p *Planner) plan() ([]model.Assignment, error) {
+func (p *Planner) plan(request PlanRequest, taskSorter TaskSorter) (*model.PlanRun, error) {
 	// Fetch developers first
 	developers, err := p.developerService.GetDevelopers()
 	if err != nil {
@@ -104,21 +194,130 @@ func (p *Planner) plan() ([]model.Assignment, error) {
 		return nil, fmt.Errorf("failed to get tasks: %w", err)
 	}
 
+	// sub-tasks are created by the planner itself and planned through their parent
+	split := splitTaskIDs(tasks)
+	lastID := lastTaskID(tasks)
+	tasks = topLevelTasks(tasks)
+
+	run := &model.PlanRun{
+		Sorter:            taskSorter.Name(),
+		Solver:            request.Solver,
+		Dependencies:      request.Dependencies,
+		TaskCount:         len(tasks),
+		DeveloperCount:    len(developers),
+		TaskSnapshot:      tasks,
+		DeveloperSnapshot: developers,
+	}
+
+	if seeded, ok := taskSorter.(SeededSorter); ok {
+		seed := seeded.Seed()
+		run.SorterSeed = &seed
+	}
+
 	if len(tasks) == 0 {
-		return nil, nil
+		return run, nil
+	}
+
+	// Sort tasks using the selected sorter
+	sortedTasks := taskSorter.Sort(tasks)
+
+	if !request.DisableSplitting {
+		sortedTasks, run.Splits, err = p.splitTasks(sortedTasks, developers, split, request.Preview, lastID)
+		if err != nil {
+			logger.Error(err)
+			return nil, fmt.Errorf("failed to split tasks: %w", err)
+		}
+	}
+
+	if request.Dependencies == DependenciesStrict {
+		dependencies, err := p.taskService.GetDependencies()
+		if err != nil {
+			logger.Error(err)
+			return nil, fmt.Errorf("failed to get task dependencies: %w", err)
+		}
+
+		applyDependencies(sortedTasks, dependencies, run.Splits)
+		sortedTasks = orderByDependencies(sortedTasks)
+	}
+
+	if request.Solver == SolverOptimal {
+		run.Assignments, run.Optimal = NewOptimalAssigner(developers, request.TimeBudget).Assign(sortedTasks)
+	} else {
+		// Send tasks in batches
+		for _, task := range sortedTasks {
+			p.channelManager.SendTask(task)
+			currentAssignments := p.channelManager.ReceiveAssignments()
+			run.Assignments = append(run.Assignments, currentAssignments...)
+		}
+	}
+
+	assigned := make(map[uint]struct{}, len(run.Assignments))
+	for _, assignment := range run.Assignments {
+		assigned[assignment.TaskID] = struct{}{}
 	}
 
-	// Sort tasks using the configured sorter
-	sortedTasks := p.taskSorter.Sort(tasks)
-	// Send tasks in batches
-	var assignments []model.Assignment
 	for _, task := range sortedTasks {
-		p.channelManager.SendTask(task)
-		currentAssignments := p.channelManager.ReceiveAssignments()
-		assignments = append(assignments, currentAssignments...)
+		if _, ok := assigned[task.ID]; ok {
+			continue
+		}
+
+		unassigned := ExplainUnassigned(task, developers)
+		for _, id := range task.DependsOn {
+			if _, ok := assigned[id]; !ok && len(developers) > 0 {
+				unassigned.Reason = ReasonBlockedByDependency
+				break
+			}
+		}
+
+		run.Unassigned = append(run.Unassigned, unassigned)
+	}
+
+	if request.Preview {
+		clearTemporaryIDs(run, lastID)
+	}
+
+	for _, assignment := range run.Assignments {
+		if assignment.WeekNumber > run.TotalWeeks {
+			run.TotalWeeks = assignment.WeekNumber
+		}
+
+		run.TotalHours += assignment.CalculatedHours
+	}
+
+	return run, nil
+}
+
+func topLevelTasks(tasks []model.Task) []model.Task {
+	result := make([]model.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.ParentID == nil {
+			result = append(result, task)
+		}
+	}
+
+	return result
+}
+
+// splitTaskIDs returns the ids of the tasks that have stored sub-tasks
+func splitTaskIDs(tasks []model.Task) map[uint]bool {
+	result := make(map[uint]bool)
+	for _, task := range tasks {
+		if task.ParentID != nil {
+			result[*task.ParentID] = true
+		}
+	}
+
+	return result
+}
+
+// lastTaskID returns the highest id of the tasks
+func lastTaskID(tasks []model.Task) uint {
+	var last uint
+	for _, task := range tasks {
+		last = max(last, task.ID)
 	}
 
-	return assignments, nil
+	return last
 }
 
 func (p *Planner) Stop() {
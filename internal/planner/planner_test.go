@@ -2,20 +2,52 @@ package planner
 
 import (
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"todo-planning/internal/model"
+	"todo-planning/internal/utility"
 )
 
 type mockTaskService struct {
-	tasks []model.Task
-	err   error
+	tasks        []model.Task
+	dependencies []model.TaskDependency
+	err          error
+	split        []uint // tasks whose sub-tasks were stored
+	unsplit      []uint // tasks whose sub-tasks were removed
 }
 
 func (m *mockTaskService) GetTasks() ([]model.Task, error) {
 	return m.tasks, m.err
 }
 
+func (m *mockTaskService) GetDependencies() ([]model.TaskDependency, error) {
+	return m.dependencies, nil
+}
+
+func (m *mockTaskService) SplitTask(task model.Task, parts int) ([]model.Task, error) {
+	m.split = append(m.split, task.ID)
+
+	subTasks := make([]model.Task, 0, parts)
+	for i := 1; i <= parts; i++ {
+		subTasks = append(subTasks, model.Task{
+			ID:                task.ID*100 + uint(i),
+			Difficulty:        task.Difficulty,
+			EstimatedDuration: task.EstimatedDuration / float64(parts),
+			ParentID:          &task.ID,
+			Sequence:          i,
+		})
+	}
+
+	return subTasks, nil
+}
+
+func (m *mockTaskService) UnsplitTask(task model.Task) error {
+	m.unsplit = append(m.unsplit, task.ID)
+	return nil
+}
+
 type mockDeveloperService struct {
 	developers []model.Developer
 	err        error
@@ -133,3 +165,559 @@ func TestPlanner_Plan(t *testing.T) {
 		})
 	}
 }
+
+type mockAssignmentService struct {
+	runs []*model.PlanRun
+	err  error
+}
+
+func (m *mockAssignmentService) CreatePlanRun(run *model.PlanRun) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	run.ID = uint(len(m.runs) + 1)
+	m.runs = append(m.runs, run)
+
+	return nil
+}
+
+func TestPlanner_CreatePlan(t *testing.T) {
+	tasks := []model.Task{
+		{ID: 1, Difficulty: 2, EstimatedDuration: 3},
+		{ID: 2, Difficulty: 1, EstimatedDuration: 4},
+	}
+	developers := []model.Developer{
+		{ID: 1, Productivity: 2},
+	}
+
+	t.Run("saves plan run", func(t *testing.T) {
+		assignmentService := &mockAssignmentService{}
+		planner := newPlanner(PlanningOptions{
+			TaskService:       &mockTaskService{tasks: tasks},
+			DeveloperService:  &mockDeveloperService{developers: developers},
+			AssignmentService: assignmentService,
+			ChannelManager:    NewDefaultChannelManager(),
+			SaveAssignments:   true,
+		})
+
+		run, err := planner.CreatePlan(PlanRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(assignmentService.runs) != 1 || assignmentService.runs[0] != run {
+			t.Fatalf("expected plan run to be saved")
+		}
+		if run.Sorter != "weight" {
+			t.Errorf("expected sorter weight, got %s", run.Sorter)
+		}
+		if run.TaskCount != 2 || run.DeveloperCount != 1 {
+			t.Errorf("expected 2 tasks and 1 developer, got %d and %d", run.TaskCount, run.DeveloperCount)
+		}
+		if len(run.Assignments) != 2 {
+			t.Fatalf("expected 2 assignments, got %d", len(run.Assignments))
+		}
+		if run.TotalHours != 5 {
+			t.Errorf("expected 5 total hours, got %f", run.TotalHours)
+		}
+		if run.TotalWeeks != 1 {
+			t.Errorf("expected 1 total week, got %d", run.TotalWeeks)
+		}
+	})
+
+	t.Run("save error", func(t *testing.T) {
+		planner := newPlanner(PlanningOptions{
+			TaskService:       &mockTaskService{tasks: tasks},
+			DeveloperService:  &mockDeveloperService{developers: developers},
+			AssignmentService: &mockAssignmentService{err: errors.New("save error")},
+			ChannelManager:    NewDefaultChannelManager(),
+			SaveAssignments:   true,
+		})
+
+		if _, err := planner.CreatePlan(PlanRequest{}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestPlanner_CreatePlanSolver(t *testing.T) {
+	tasks := []model.Task{
+		{ID: 1, Difficulty: 1, EstimatedDuration: 36},
+		{ID: 2, Difficulty: 1, EstimatedDuration: 20},
+		{ID: 3, Difficulty: 1, EstimatedDuration: 11},
+		{ID: 4, Difficulty: 1, EstimatedDuration: 14},
+		{ID: 5, Difficulty: 1, EstimatedDuration: 14},
+		{ID: 6, Difficulty: 1, EstimatedDuration: 15},
+		{ID: 7, Difficulty: 1, EstimatedDuration: 23},
+	}
+	developers := []model.Developer{
+		{ID: 1, Productivity: 1},
+		{ID: 2, Productivity: 2},
+	}
+
+	newTestPlanner := func() *Planner {
+		return newPlanner(PlanningOptions{
+			TaskService:      &mockTaskService{tasks: tasks},
+			DeveloperService: &mockDeveloperService{developers: developers},
+			ChannelManager:   NewDefaultChannelManager(),
+		})
+	}
+
+	greedy, err := newTestPlanner().CreatePlan(PlanRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if greedy.Solver != SolverGreedy {
+		t.Errorf("expected solver %s, got %s", SolverGreedy, greedy.Solver)
+	}
+
+	optimal, err := newTestPlanner().CreatePlan(PlanRequest{Solver: SolverOptimal, TimeBudget: time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if optimal.Solver != SolverOptimal || !optimal.Optimal {
+		t.Errorf("expected proven optimal plan, got solver %s optimal %v", optimal.Solver, optimal.Optimal)
+	}
+	if len(optimal.Assignments) != len(tasks) {
+		t.Errorf("expected %d assignments, got %d", len(tasks), len(optimal.Assignments))
+	}
+	if optimal.TotalWeeks >= greedy.TotalWeeks {
+		t.Errorf("expected optimal plan to need fewer than %d weeks, got %d", greedy.TotalWeeks, optimal.TotalWeeks)
+	}
+
+	if _, err := newTestPlanner().CreatePlan(PlanRequest{Solver: "unknown"}); !errors.Is(err, ErrUnknownSolver) {
+		t.Errorf("expected unknown solver error, got %v", err)
+	}
+}
+
+func TestPlanner_CreatePlanSorter(t *testing.T) {
+	tasks := []model.Task{
+		{ID: 1, Difficulty: 1, EstimatedDuration: 6, Priority: 1},
+		{ID: 2, Difficulty: 1, EstimatedDuration: 2},
+		{ID: 3, Difficulty: 1, EstimatedDuration: 4, Priority: 5},
+	}
+	developers := []model.Developer{
+		{ID: 1, Productivity: 1},
+	}
+
+	newTestPlanner := func() *Planner {
+		return newPlanner(PlanningOptions{
+			TaskService:      &mockTaskService{tasks: tasks},
+			DeveloperService: &mockDeveloperService{developers: developers},
+			ChannelManager:   NewDefaultChannelManager(),
+		})
+	}
+
+	taskIDs := func(run *model.PlanRun) []uint {
+		ids := make([]uint, len(run.Assignments))
+		for i, assignment := range run.Assignments {
+			ids[i] = assignment.TaskID
+		}
+		return ids
+	}
+
+	t.Run("named sorter", func(t *testing.T) {
+		run, err := newTestPlanner().CreatePlan(PlanRequest{Sorter: SorterPriorityFirst})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if run.Sorter != SorterPriorityFirst {
+			t.Errorf("expected sorter %s, got %s", SorterPriorityFirst, run.Sorter)
+		}
+		if run.SorterSeed != nil {
+			t.Errorf("expected no seed, got %d", *run.SorterSeed)
+		}
+		if ids := taskIDs(run); !reflect.DeepEqual(ids, []uint{3, 1, 2}) {
+			t.Errorf("expected tasks in order [3 1 2], got %v", ids)
+		}
+	})
+
+	t.Run("random sorter records its seed", func(t *testing.T) {
+		seed := uint64(42)
+		first, err := newTestPlanner().CreatePlan(PlanRequest{Sorter: SorterRandom, Seed: &seed})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if first.SorterSeed == nil || *first.SorterSeed != seed {
+			t.Fatalf("expected seed %d, got %v", seed, first.SorterSeed)
+		}
+
+		second, err := newTestPlanner().CreatePlan(PlanRequest{Sorter: SorterRandom, Seed: &seed})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(taskIDs(first), taskIDs(second)) {
+			t.Errorf("expected the same order for the same seed, got %v and %v", taskIDs(first), taskIDs(second))
+		}
+
+		generated, err := newTestPlanner().CreatePlan(PlanRequest{Sorter: SorterRandom})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if generated.SorterSeed == nil {
+			t.Error("expected a generated seed to be recorded")
+		}
+	})
+
+	t.Run("optimal solver keeps the order of the sorter", func(t *testing.T) {
+		day := func(d int) *time.Time {
+			deadline := time.Date(2024, time.June, d, 0, 0, 0, 0, time.UTC)
+			return &deadline
+		}
+
+		// greedy needs 3 weeks, 2 are enough when task 5 shares a week with the large tasks
+		planner := newPlanner(PlanningOptions{
+			TaskService: &mockTaskService{tasks: []model.Task{
+				{ID: 1, Difficulty: 1, EstimatedDuration: 30, Deadline: day(20)},
+				{ID: 2, Difficulty: 1, EstimatedDuration: 20, Deadline: day(25)},
+				{ID: 3, Difficulty: 1, EstimatedDuration: 15, Deadline: day(15)},
+				{ID: 4, Difficulty: 1, EstimatedDuration: 15, Deadline: day(10)},
+				{ID: 5, Difficulty: 1, EstimatedDuration: 10, Deadline: day(5)},
+			}},
+			DeveloperService: &mockDeveloperService{developers: []model.Developer{{ID: 1, Productivity: 1, WeeklyCapacity: utility.ToPointer(45.0)}}},
+			ChannelManager:   NewDefaultChannelManager(),
+		})
+
+		run, err := planner.CreatePlan(PlanRequest{Solver: SolverOptimal, Sorter: SorterDeadlineFirst, TimeBudget: time.Second})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if run.Sorter != SorterDeadlineFirst || !run.Optimal || run.TotalWeeks != 2 {
+			t.Fatalf("expected an optimal plan of 2 weeks sorted %s, got %d weeks sorted %s, optimal %v", SorterDeadlineFirst, run.TotalWeeks, run.Sorter, run.Optimal)
+		}
+
+		weeks := make(map[uint]int)
+		for _, assignment := range run.Assignments {
+			weeks[assignment.TaskID] = assignment.WeekNumber
+		}
+		// the tasks due first go into the first week as long as the plan keeps its 2 weeks
+		if weeks[5] != 1 || weeks[4] != 1 {
+			t.Errorf("expected the tasks due first in week 1, got weeks %v", weeks)
+		}
+	})
+
+	if _, err := newTestPlanner().CreatePlan(PlanRequest{Sorter: "unknown"}); !errors.Is(err, ErrUnknownSorter) {
+		t.Errorf("expected unknown sorter error, got %v", err)
+	}
+}
+
+func TestPlanner_CreatePlanSplitting(t *testing.T) {
+	tasks := []model.Task{
+		{ID: 1, Difficulty: 4, EstimatedDuration: 50}, // 200 effort, 100 hours for the faster developer
+		{ID: 2, Difficulty: 1, EstimatedDuration: 10},
+	}
+	developers := []model.Developer{
+		{ID: 1, Productivity: 1},
+		{ID: 2, Productivity: 2},
+	}
+
+	for _, solver := range []string{SolverGreedy, SolverOptimal} {
+		t.Run(solver, func(t *testing.T) {
+			planner := newPlanner(PlanningOptions{
+				TaskService:      &mockTaskService{tasks: tasks},
+				DeveloperService: &mockDeveloperService{developers: developers},
+				ChannelManager:   NewDefaultChannelManager(),
+			})
+
+			run, err := planner.CreatePlan(PlanRequest{Solver: solver})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(run.Splits) != 1 {
+				t.Fatalf("expected 1 split, got %d", len(run.Splits))
+			}
+			if run.Splits[0].TaskID != 1 || run.Splits[0].Parts != 3 {
+				t.Errorf("expected task 1 to be split into 3 parts, got %+v", run.Splits[0])
+			}
+			if len(run.Assignments) != 4 {
+				t.Fatalf("expected 4 assignments, got %d", len(run.Assignments))
+			}
+
+			partWeeks := make(map[int]int)
+			for _, assignment := range run.Assignments {
+				if assignment.Task.ParentID != nil {
+					partWeeks[assignment.Task.Sequence] = assignment.WeekNumber
+				}
+			}
+			for sequence := 2; sequence <= 3; sequence++ {
+				if partWeeks[sequence] <= partWeeks[sequence-1] {
+					t.Errorf("expected part %d to be scheduled after part %d, got weeks %v", sequence, sequence-1, partWeeks)
+				}
+			}
+		})
+	}
+
+	t.Run("splitting disabled", func(t *testing.T) {
+		planner := newPlanner(PlanningOptions{
+			TaskService:      &mockTaskService{tasks: tasks},
+			DeveloperService: &mockDeveloperService{developers: developers},
+			ChannelManager:   NewDefaultChannelManager(),
+		})
+
+		run, err := planner.CreatePlan(PlanRequest{DisableSplitting: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(run.Splits) != 0 {
+			t.Errorf("expected no splits, got %d", len(run.Splits))
+		}
+		if len(run.Assignments) != 1 {
+			t.Errorf("expected 1 assignment, got %d", len(run.Assignments))
+		}
+	})
+
+	t.Run("task that fits again", func(t *testing.T) {
+		// task 2 was split by an earlier plan and has been re-estimated since
+		stale := append([]model.Task{}, tasks...)
+		stale = append(stale,
+			model.Task{ID: 201, Difficulty: 1, EstimatedDuration: 5, ParentID: &tasks[1].ID, Sequence: 1},
+			model.Task{ID: 202, Difficulty: 1, EstimatedDuration: 5, ParentID: &tasks[1].ID, Sequence: 2},
+		)
+		taskService := &mockTaskService{tasks: stale}
+		planner := newPlanner(PlanningOptions{
+			TaskService:      taskService,
+			DeveloperService: &mockDeveloperService{developers: developers},
+			ChannelManager:   NewDefaultChannelManager(),
+		})
+
+		run, err := planner.CreatePlan(PlanRequest{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(taskService.unsplit, []uint{2}) {
+			t.Errorf("expected the sub-tasks of task 2 to be removed, got %v", taskService.unsplit)
+		}
+		for _, assignment := range run.Assignments {
+			if assignment.TaskID == 201 || assignment.TaskID == 202 {
+				t.Errorf("expected the stale sub-tasks not to be planned, got %+v", assignment)
+			}
+		}
+	})
+
+	t.Run("preview", func(t *testing.T) {
+		stale := append([]model.Task{}, tasks...)
+		stale = append(stale, model.Task{ID: 201, Difficulty: 1, EstimatedDuration: 5, ParentID: &tasks[1].ID, Sequence: 1})
+		taskService := &mockTaskService{tasks: stale}
+		assignmentService := &mockAssignmentService{}
+		planner := newPlanner(PlanningOptions{
+			TaskService:       taskService,
+			DeveloperService:  &mockDeveloperService{developers: developers},
+			AssignmentService: assignmentService,
+			ChannelManager:    NewDefaultChannelManager(),
+			SaveAssignments:   true,
+		})
+
+		run, err := planner.CreatePlan(PlanRequest{Preview: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(assignmentService.runs) != 0 || len(taskService.split) != 0 || len(taskService.unsplit) != 0 {
+			t.Errorf("expected nothing to be stored, got runs %v, splits %v and unsplits %v", assignmentService.runs, taskService.split, taskService.unsplit)
+		}
+		if len(run.Splits) != 1 || run.Splits[0].Parts != 3 || len(run.Splits[0].SubTaskIDs) != 0 {
+			t.Fatalf("expected task 1 to be split into 3 parts without ids, got %+v", run.Splits)
+		}
+		if len(run.Assignments) != 4 {
+			t.Fatalf("expected 4 assignments, got %d", len(run.Assignments))
+		}
+
+		partWeeks := make(map[int]int)
+		for _, assignment := range run.Assignments {
+			if assignment.Task.ParentID == nil {
+				continue
+			}
+			if assignment.TaskID != 0 || assignment.Task.ID != 0 || len(assignment.Task.DependsOn) != 0 {
+				t.Errorf("expected part %d to have no id, got %+v", assignment.Task.Sequence, assignment)
+			}
+			partWeeks[assignment.Task.Sequence] = assignment.WeekNumber
+		}
+		for sequence := 2; sequence <= 3; sequence++ {
+			if partWeeks[sequence] <= partWeeks[sequence-1] {
+				t.Errorf("expected part %d to be scheduled after part %d, got weeks %v", sequence, sequence-1, partWeeks)
+			}
+		}
+	})
+}
+
+func TestPlanner_CreatePlanUnassigned(t *testing.T) {
+	tests := []struct {
+		name       string
+		tasks      []model.Task
+		developers []model.Developer
+		request    PlanRequest
+		expected   []string
+	}{
+		{
+			name:       "no developers",
+			tasks:      []model.Task{{ID: 1, Difficulty: 1, EstimatedDuration: 1}},
+			developers: []model.Developer{},
+			expected:   []string{ReasonNoDevelopers},
+		},
+		{
+			name:       "zero productivity",
+			tasks:      []model.Task{{ID: 1, Difficulty: 1, EstimatedDuration: 1}},
+			developers: []model.Developer{{ID: 1, Productivity: 0}},
+			expected:   []string{ReasonZeroProductivity},
+		},
+		{
+			name: "exceeds capacity",
+			tasks: []model.Task{
+				{ID: 1, Difficulty: 1, EstimatedDuration: 1},
+				{ID: 2, Difficulty: 2, EstimatedDuration: 50},
+			},
+			developers: []model.Developer{{ID: 1, Productivity: 1}},
+			request:    PlanRequest{DisableSplitting: true},
+			expected:   []string{ReasonExceedsCapacity},
+		},
+		{
+			name:       "everything assigned",
+			tasks:      []model.Task{{ID: 1, Difficulty: 1, EstimatedDuration: 1}},
+			developers: []model.Developer{{ID: 1, Productivity: 1}},
+			expected:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planner := newPlanner(PlanningOptions{
+				TaskService:      &mockTaskService{tasks: tt.tasks},
+				DeveloperService: &mockDeveloperService{developers: tt.developers},
+				ChannelManager:   NewDefaultChannelManager(),
+			})
+
+			run, err := planner.CreatePlan(tt.request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(run.Unassigned) != len(tt.expected) {
+				t.Fatalf("expected %d unassigned tasks, got %d", len(tt.expected), len(run.Unassigned))
+			}
+			for i, reason := range tt.expected {
+				if run.Unassigned[i].Reason != reason {
+					t.Errorf("expected reason %s, got %s", reason, run.Unassigned[i].Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanner_CreatePlanDependencies(t *testing.T) {
+	// task 1 is the smallest, so without dependencies it would be planned last
+	tasks := []model.Task{
+		{ID: 1, Difficulty: 1, EstimatedDuration: 5},
+		{ID: 2, Difficulty: 1, EstimatedDuration: 40},
+		{ID: 3, Difficulty: 1, EstimatedDuration: 30},
+	}
+	dependencies := []model.TaskDependency{
+		{TaskID: 2, DependsOnID: 1},
+		{TaskID: 3, DependsOnID: 2},
+	}
+	developers := []model.Developer{
+		{ID: 1, Productivity: 1},
+		{ID: 2, Productivity: 1},
+	}
+
+	placements := func(run *model.PlanRun) map[uint]model.Assignment {
+		result := make(map[uint]model.Assignment)
+		for _, assignment := range run.Assignments {
+			result[assignment.TaskID] = assignment
+		}
+
+		return result
+	}
+
+	for _, solver := range []string{SolverGreedy, SolverOptimal} {
+		t.Run(solver, func(t *testing.T) {
+			planner := newPlanner(PlanningOptions{
+				TaskService:      &mockTaskService{tasks: tasks, dependencies: dependencies},
+				DeveloperService: &mockDeveloperService{developers: developers},
+				ChannelManager:   NewDefaultChannelManager(),
+			})
+
+			run, err := planner.CreatePlan(PlanRequest{Solver: solver})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if run.Dependencies != DependenciesStrict {
+				t.Errorf("expected dependency mode %s, got %s", DependenciesStrict, run.Dependencies)
+			}
+
+			assignments := placements(run)
+			if len(assignments) != 3 {
+				t.Fatalf("expected 3 assignments, got %d", len(assignments))
+			}
+
+			for _, dependency := range dependencies {
+				task, prerequisite := assignments[dependency.TaskID], assignments[dependency.DependsOnID]
+				sameDeveloper := task.DeveloperID == prerequisite.DeveloperID
+				if task.WeekNumber < prerequisite.WeekNumber || (!sameDeveloper && task.WeekNumber == prerequisite.WeekNumber) {
+					t.Errorf("task %d in week %d starts before task %d in week %d is finished",
+						dependency.TaskID, task.WeekNumber, dependency.DependsOnID, prerequisite.WeekNumber)
+				}
+			}
+		})
+	}
+
+	t.Run("ignore dependencies", func(t *testing.T) {
+		planner := newPlanner(PlanningOptions{
+			TaskService:      &mockTaskService{tasks: tasks, dependencies: dependencies},
+			DeveloperService: &mockDeveloperService{developers: developers},
+			ChannelManager:   NewDefaultChannelManager(),
+		})
+
+		run, err := planner.CreatePlan(PlanRequest{Dependencies: DependenciesIgnore})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if run.TotalWeeks != 1 {
+			t.Errorf("expected independent tasks to fit into 1 week, got %d", run.TotalWeeks)
+		}
+	})
+
+	t.Run("blocked by dependency", func(t *testing.T) {
+		planner := newPlanner(PlanningOptions{
+			TaskService: &mockTaskService{
+				tasks: []model.Task{
+					{ID: 1, Difficulty: 2, EstimatedDuration: 50},
+					{ID: 2, Difficulty: 1, EstimatedDuration: 5},
+				},
+				dependencies: []model.TaskDependency{{TaskID: 2, DependsOnID: 1}},
+			},
+			DeveloperService: &mockDeveloperService{developers: developers},
+			ChannelManager:   NewDefaultChannelManager(),
+		})
+
+		run, err := planner.CreatePlan(PlanRequest{DisableSplitting: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(run.Unassigned) != 2 {
+			t.Fatalf("expected 2 unassigned tasks, got %d", len(run.Unassigned))
+		}
+		if run.Unassigned[1].Task.ID != 2 || run.Unassigned[1].Reason != ReasonBlockedByDependency {
+			t.Errorf("expected task 2 to be blocked by dependency, got %+v", run.Unassigned[1])
+		}
+	})
+
+	t.Run("unknown dependency mode", func(t *testing.T) {
+		planner := newPlanner(PlanningOptions{
+			TaskService:      &mockTaskService{tasks: tasks},
+			DeveloperService: &mockDeveloperService{developers: developers},
+			ChannelManager:   NewDefaultChannelManager(),
+		})
+
+		if _, err := planner.CreatePlan(PlanRequest{Dependencies: "unknown"}); !errors.Is(err, ErrUnknownDependencyMode) {
+			t.Errorf("expected unknown dependency mode error, got %v", err)
+		}
+	})
+}
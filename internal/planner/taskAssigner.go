@@ -6,23 +6,35 @@ import (
 	"todo-planning/internal/model"
 )
 
+// Reasons a task can be left unassigned
+const (
+	ReasonNoDevelopers        = "no_developers"
+	ReasonZeroProductivity    = "zero_productivity"
+	ReasonExceedsCapacity     = "exceeds_capacity"
+	ReasonBlockedByDependency = "blocked_by_dependency"
+)
+
 // TaskAssigner handles the core task assignment logic
 type TaskAssigner struct {
 	developers []model.Developer
 	devStates  []*devState
+	placements map[uint]placement // task -> where it was assigned
+}
+
+type placement struct {
+	developerID uint
+	week        int
 }
 
 func NewTaskAssigner(developers []model.Developer) *TaskAssigner {
 	devStates := make([]*devState, 0, len(developers))
 	for _, dev := range developers {
-		devStates = append(devStates, &devState{
-			Developer: dev,
-			WeekLoads: make(map[int]float64, 10),
-		})
+		devStates = append(devStates, newDevState(dev))
 	}
 	return &TaskAssigner{
 		developers: developers,
 		devStates:  devStates,
+		placements: make(map[uint]placement),
 	}
 }
 
@@ -32,6 +44,8 @@ func (ta *TaskAssigner) AssignTask(task model.Task) *model.Assignment {
 		return nil
 	}
 
+	ta.placements[task.ID] = placement{developerID: bestDev.Developer.ID, week: week}
+
 	return &model.Assignment{
 		TaskID:          task.ID,
 		DeveloperID:     bestDev.Developer.ID,
@@ -54,16 +68,18 @@ func (ta *TaskAssigner) FindBestFit(task model.Task) (*devState, int, float64) {
 
 	for _, dev := range ta.devStates {
 		hoursNeeded := CalculateHoursNeeded(taskEffort, dev.Developer)
-		if hoursNeeded > MaxHoursPerWeek {
+		if hoursNeeded > dev.MaxCapacity() {
 			continue
 		}
 		// Find the first week where the task can fit
-		week := 1
-		for {
-			if dev.WeekLoads[week]+hoursNeeded <= MaxHoursPerWeek {
-				break
-			}
-			week++
+		week, ok := ta.earliestWeek(task, dev.Developer.ID)
+		if !ok {
+			continue
+		}
+
+		week, ok = dev.FirstFit(week, hoursNeeded)
+		if !ok {
+			continue
 		}
 		changeDev := false
 		if week < minTotal {
@@ -123,6 +139,71 @@ func (ta *TaskAssigner) FindBestFit(task model.Task) (*devState, int, float64) {
 	return bestDev, bestWeek, hoursNeeded
 }
 
+// earliestWeek returns the first week a developer may work on a task. A task
+// starts after the tasks it depends on: in the same week when the developer
+// finished them, in the next week otherwise. It returns false while one of
+// them hasn't been assigned.
+func (ta *TaskAssigner) earliestWeek(task model.Task, developerID uint) (int, bool) {
+	week := 1
+	for _, id := range task.DependsOn {
+		p, ok := ta.placements[id]
+		if !ok {
+			return 0, false
+		}
+
+		earliest := p.week + 1
+		if p.developerID == developerID {
+			earliest = p.week
+		}
+
+		if earliest > week {
+			week = earliest
+		}
+	}
+
+	return week, true
+}
+
+// ExplainUnassigned describes why a task couldn't be assigned to any of the
+// developers. The minimum productivity is based on the largest regular
+// weekly capacity.
+func ExplainUnassigned(task model.Task, developers []model.Developer) model.UnassignedTask {
+	capacity := float64(MaxHoursPerWeek)
+	if len(developers) > 0 {
+		capacity = 0
+		for _, developer := range developers {
+			capacity = math.Max(capacity, WeeklyCapacity(developer))
+		}
+	}
+
+	// nobody has regular hours, the productivity is given for a full week
+	if capacity == 0 {
+		capacity = MaxHoursPerWeek
+	}
+
+	unassigned := model.UnassignedTask{
+		Task:            task,
+		Reason:          ReasonExceedsCapacity,
+		MinProductivity: CalculateTaskEffort(task) / capacity,
+	}
+
+	if len(developers) == 0 {
+		unassigned.Reason = ReasonNoDevelopers
+
+		return unassigned
+	}
+
+	for _, developer := range developers {
+		if developer.Productivity > 0 {
+			return unassigned
+		}
+	}
+
+	unassigned.Reason = ReasonZeroProductivity
+
+	return unassigned
+}
+
 // CalculateTaskEffort calculates the effort needed for a task
 func CalculateTaskEffort(task model.Task) float64 {
 	return float64(task.Difficulty * task.EstimatedDuration)
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"todo-planning/internal/model"
+	"todo-planning/internal/utility"
 )
 
 func TestCalculateTaskEffort(t *testing.T) {
@@ -168,3 +169,150 @@ func TestTaskAssigner_AssignTask(t *testing.T) {
 		t.Errorf("expected hours 3.0, got %f", assignment.CalculatedHours)
 	}
 }
+
+func TestExplainUnassigned(t *testing.T) {
+	task := model.Task{ID: 1, Difficulty: 9, EstimatedDuration: 10}
+
+	tests := []struct {
+		name       string
+		developers []model.Developer
+		expected   string
+	}{
+		{
+			name:       "no developers",
+			developers: []model.Developer{},
+			expected:   ReasonNoDevelopers,
+		},
+		{
+			name:       "zero productivity",
+			developers: []model.Developer{{ID: 1, Productivity: 0}},
+			expected:   ReasonZeroProductivity,
+		},
+		{
+			name:       "exceeds capacity",
+			developers: []model.Developer{{ID: 1, Productivity: 0}, {ID: 2, Productivity: 1}},
+			expected:   ReasonExceedsCapacity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unassigned := ExplainUnassigned(task, tt.developers)
+
+			if unassigned.Reason != tt.expected {
+				t.Errorf("expected reason %s, got %s", tt.expected, unassigned.Reason)
+			}
+			if unassigned.Task.ID != task.ID {
+				t.Errorf("expected task ID %d, got %d", task.ID, unassigned.Task.ID)
+			}
+			if unassigned.MinProductivity != 2.0 {
+				t.Errorf("expected min productivity 2.0, got %f", unassigned.MinProductivity)
+			}
+		})
+	}
+}
+
+func TestTaskAssigner_AssignTaskDependencies(t *testing.T) {
+	developers := []model.Developer{
+		{ID: 1, Productivity: 1},
+		{ID: 2, Productivity: 1},
+	}
+	taskAssigner := NewTaskAssigner(developers)
+
+	// no prerequisite assigned yet
+	if assignment := taskAssigner.AssignTask(model.Task{ID: 2, Difficulty: 1, EstimatedDuration: 5, DependsOn: []uint{1}}); assignment != nil {
+		t.Fatalf("expected task to wait for its prerequisite, got %+v", assignment)
+	}
+
+	first := taskAssigner.AssignTask(model.Task{ID: 1, Difficulty: 1, EstimatedDuration: 10})
+	if first == nil || first.DeveloperID != 1 || first.WeekNumber != 1 {
+		t.Fatalf("expected task 1 in week 1 for developer 1, got %+v", first)
+	}
+
+	// the same developer continues in the same week, anyone else has to wait a week
+	second := taskAssigner.AssignTask(model.Task{ID: 2, Difficulty: 1, EstimatedDuration: 5, DependsOn: []uint{1}})
+	if second == nil || second.DeveloperID != 1 || second.WeekNumber != 1 {
+		t.Fatalf("expected task 2 in week 1 for developer 1, got %+v", second)
+	}
+
+	taskAssigner.devStates[0].WeekLoads[1] = MaxHoursPerWeek
+	third := taskAssigner.AssignTask(model.Task{ID: 3, Difficulty: 1, EstimatedDuration: 5, DependsOn: []uint{2}})
+	if third == nil || third.WeekNumber != 2 {
+		t.Fatalf("expected task 3 in week 2, got %+v", third)
+	}
+}
+
+func TestTaskAssigner_AssignTaskAvailability(t *testing.T) {
+	developers := []model.Developer{
+		{
+			ID:             1,
+			Productivity:   1,
+			WeeklyCapacity: utility.ToPointer(20.0),
+			Availability: []model.DeveloperAvailability{
+				{DeveloperID: 1, WeekNumber: 1, Hours: 0, Reason: "vacation"},
+				{DeveloperID: 1, WeekNumber: 3, Hours: 30, Reason: "overtime"},
+			},
+		},
+	}
+	taskAssigner := NewTaskAssigner(developers)
+
+	// week 1 is a vacation
+	first := taskAssigner.AssignTask(model.Task{ID: 1, Difficulty: 1, EstimatedDuration: 15})
+	if first == nil || first.WeekNumber != 2 {
+		t.Fatalf("expected task 1 in week 2, got %+v", first)
+	}
+
+	// only the overtime week has room for more than the regular capacity
+	second := taskAssigner.AssignTask(model.Task{ID: 2, Difficulty: 1, EstimatedDuration: 25})
+	if second == nil || second.WeekNumber != 3 {
+		t.Fatalf("expected task 2 in week 3, got %+v", second)
+	}
+
+	if third := taskAssigner.AssignTask(model.Task{ID: 3, Difficulty: 1, EstimatedDuration: 25}); third != nil {
+		t.Fatalf("expected task 3 to exceed every remaining week, got %+v", third)
+	}
+}
+
+func TestDevState_Capacity(t *testing.T) {
+	state := newDevState(model.Developer{
+		ID:           1,
+		Productivity: 1,
+		Availability: []model.DeveloperAvailability{
+			{DeveloperID: 1, WeekNumber: 2, Hours: 10},
+		},
+	})
+
+	if capacity := state.Capacity(1); capacity != MaxHoursPerWeek {
+		t.Errorf("expected default capacity %d, got %f", MaxHoursPerWeek, capacity)
+	}
+	if capacity := state.Capacity(2); capacity != 10 {
+		t.Errorf("expected capacity 10 in week 2, got %f", capacity)
+	}
+
+	if week, ok := state.FirstFit(2, 20); !ok || week != 3 {
+		t.Errorf("expected 20 hours to fit in week 3, got week %d (%v)", week, ok)
+	}
+	if _, ok := state.FirstFit(1, MaxHoursPerWeek+1); ok {
+		t.Error("expected hours above every week's capacity not to fit")
+	}
+
+	// with a weekly capacity of 0 only the overridden weeks have room
+	idle := newDevState(model.Developer{
+		ID:             2,
+		Productivity:   1,
+		WeeklyCapacity: utility.ToPointer(0.0),
+		Availability: []model.DeveloperAvailability{
+			{DeveloperID: 2, WeekNumber: 3, Hours: 8},
+		},
+	})
+
+	if capacity := idle.Capacity(1); capacity != 0 {
+		t.Errorf("expected no capacity in week 1, got %f", capacity)
+	}
+	if week, ok := idle.FirstFit(1, 8); !ok || week != 3 {
+		t.Errorf("expected 8 hours to fit in week 3, got week %d (%v)", week, ok)
+	}
+	if _, ok := idle.FirstFit(4, 1); ok {
+		t.Error("expected no room after the last override")
+	}
+}
@@ -0,0 +1,106 @@
+package planner
+
+import (
+	"todo-planning/internal/model"
+)
+
+// Dependency modes that can be selected for a plan run
+const (
+	// DependenciesStrict schedules every task after the tasks it depends on
+	DependenciesStrict = "strict"
+	// DependenciesIgnore plans tasks as if they were independent
+	DependenciesIgnore = "ignore"
+)
+
+// applyDependencies adds the planned tasks every task has to wait for to its
+// DependsOn list. A dependency on a split task waits for its last part, and
+// the first part of a split task inherits the dependencies of its parent.
+// Dependencies on tasks that aren't part of the plan are dropped.
+func applyDependencies(tasks []model.Task, dependencies []model.TaskDependency, splits []model.TaskSplit) {
+	planned := make(map[uint]int, len(tasks))
+	for i, task := range tasks {
+		planned[task.ID] = i
+	}
+
+	firstParts := make(map[uint]uint, len(splits))
+	lastParts := make(map[uint]uint, len(splits))
+	for _, split := range splits {
+		if len(split.SubTaskIDs) > 0 {
+			firstParts[split.TaskID] = split.SubTaskIDs[0]
+			lastParts[split.TaskID] = split.SubTaskIDs[len(split.SubTaskIDs)-1]
+		}
+	}
+
+	for _, dependency := range dependencies {
+		taskID, dependsOnID := dependency.TaskID, dependency.DependsOnID
+		if first, ok := firstParts[taskID]; ok {
+			taskID = first
+		}
+
+		if last, ok := lastParts[dependsOnID]; ok {
+			dependsOnID = last
+		}
+
+		i, ok := planned[taskID]
+		if _, prerequisitePlanned := planned[dependsOnID]; !ok || !prerequisitePlanned {
+			continue
+		}
+
+		tasks[i].DependsOn = append(tasks[i].DependsOn, dependsOnID)
+	}
+}
+
+// orderByDependencies returns the tasks in an order where every task comes
+// after the tasks it depends on and otherwise keeps the given order. Tasks on
+// a dependency cycle can't be ordered and are moved to the end.
+func orderByDependencies(tasks []model.Task) []model.Task {
+	var (
+		index      = make(map[uint]int, len(tasks))
+		waiting    = make([]int, len(tasks))
+		dependents = make([][]int, len(tasks))
+		done       = make([]bool, len(tasks))
+		result     = make([]model.Task, 0, len(tasks))
+	)
+
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+
+	for i, task := range tasks {
+		for _, id := range task.DependsOn {
+			if j, ok := index[id]; ok {
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+			}
+		}
+	}
+
+	// repeatedly take the first task in the given order that has nothing to wait for
+	for len(result) < len(tasks) {
+		next := -1
+		for i := range tasks {
+			if !done[i] && waiting[i] == 0 {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			break
+		}
+
+		done[next] = true
+		result = append(result, tasks[next])
+		for _, dependent := range dependents[next] {
+			waiting[dependent]--
+		}
+	}
+
+	for i, task := range tasks {
+		if !done[i] {
+			result = append(result, task)
+		}
+	}
+
+	return result
+}
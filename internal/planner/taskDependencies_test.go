@@ -0,0 +1,85 @@
+package planner
+
+import (
+	"reflect"
+	"testing"
+
+	"todo-planning/internal/model"
+)
+
+func TestApplyDependencies(t *testing.T) {
+	parent := uint(2)
+	tasks := []model.Task{
+		{ID: 1},
+		{ID: 21, ParentID: &parent, Sequence: 1},
+		{ID: 22, ParentID: &parent, Sequence: 2, DependsOn: []uint{21}},
+		{ID: 3},
+	}
+	splits := []model.TaskSplit{
+		{TaskID: 2, Parts: 2, SubTaskIDs: []uint{21, 22}},
+	}
+	dependencies := []model.TaskDependency{
+		{TaskID: 2, DependsOnID: 1},  // the first part waits for task 1
+		{TaskID: 3, DependsOnID: 2},  // task 3 waits for the last part
+		{TaskID: 3, DependsOnID: 99}, // task 99 isn't planned
+	}
+
+	applyDependencies(tasks, dependencies, splits)
+
+	expected := [][]uint{nil, {1}, {21}, {22}}
+	for i, task := range tasks {
+		if !reflect.DeepEqual(task.DependsOn, expected[i]) {
+			t.Errorf("task %d: expected dependencies %v, got %v", task.ID, expected[i], task.DependsOn)
+		}
+	}
+}
+
+func TestOrderByDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		tasks    []model.Task
+		expected []uint
+	}{
+		{
+			name: "keeps order without dependencies",
+			tasks: []model.Task{
+				{ID: 1}, {ID: 2}, {ID: 3},
+			},
+			expected: []uint{1, 2, 3},
+		},
+		{
+			name: "moves prerequisites first",
+			tasks: []model.Task{
+				{ID: 1, DependsOn: []uint{3}},
+				{ID: 2},
+				{ID: 3, DependsOn: []uint{4}},
+				{ID: 4},
+			},
+			expected: []uint{2, 4, 3, 1},
+		},
+		{
+			name: "cycle is moved to the end",
+			tasks: []model.Task{
+				{ID: 1, DependsOn: []uint{2}},
+				{ID: 2, DependsOn: []uint{1}},
+				{ID: 3},
+			},
+			expected: []uint{3, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := orderByDependencies(tt.tasks)
+
+			ids := make([]uint, 0, len(result))
+			for _, task := range result {
+				ids = append(ids, task.ID)
+			}
+
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("expected order %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
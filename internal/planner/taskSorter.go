@@ -1,18 +1,103 @@
 package planner
 
 import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
 	"sort"
+	"sync"
 	"todo-planning/internal/model"
 )
 
 // TaskSorter defines the interface for different task sorting strategies
 type TaskSorter interface {
+	Name() string
 	Sort(tasks []model.Task) []model.Task
 }
 
+// SeededSorter is a sorter whose order depends on a seed, planning with the
+// same seed gives the same order
+type SeededSorter interface {
+	TaskSorter
+	Seed() uint64
+}
+
+// Names of the registered sorters
+const (
+	SorterWeight        = "weight"
+	SorterShortestFirst = "shortest-first"
+	SorterPriorityFirst = "priority-first"
+	SorterDeadlineFirst = "deadline-first"
+	SorterWSJF          = "wsjf"
+	SorterRandom        = "random"
+)
+
+var ErrUnknownSorter = errors.New("unknown sorter")
+
+// SorterFactory builds a sorter, the seed is only used by seeded sorters
+type SorterFactory func(seed uint64) TaskSorter
+
+var (
+	sortersMu sync.RWMutex
+	sorters   = make(map[string]SorterFactory)
+)
+
+// RegisterSorter makes a sorting strategy available under the given name. It
+// panics when the name is registered twice.
+func RegisterSorter(name string, factory SorterFactory) {
+	sortersMu.Lock()
+	defer sortersMu.Unlock()
+
+	if _, ok := sorters[name]; ok {
+		panic(fmt.Sprintf("sorter %s is already registered", name))
+	}
+
+	sorters[name] = factory
+}
+
+// NewSorter builds a registered sorter
+func NewSorter(name string, seed uint64) (TaskSorter, error) {
+	sortersMu.RLock()
+	factory, ok := sorters[name]
+	sortersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSorter, name)
+	}
+
+	return factory(seed), nil
+}
+
+// Sorters returns the names of the registered sorters
+func Sorters() []string {
+	sortersMu.RLock()
+	defer sortersMu.RUnlock()
+
+	names := make([]string, 0, len(sorters))
+	for name := range sorters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func init() {
+	RegisterSorter(SorterWeight, func(uint64) TaskSorter { return &DefaultTaskSorter{} })
+	RegisterSorter(SorterShortestFirst, func(uint64) TaskSorter { return &ShortestFirstSorter{} })
+	RegisterSorter(SorterPriorityFirst, func(uint64) TaskSorter { return &PriorityFirstSorter{} })
+	RegisterSorter(SorterDeadlineFirst, func(uint64) TaskSorter { return &DeadlineFirstSorter{} })
+	RegisterSorter(SorterWSJF, func(uint64) TaskSorter { return &WSJFSorter{} })
+	RegisterSorter(SorterRandom, func(seed uint64) TaskSorter { return NewRandomSorter(seed) })
+}
+
 // DefaultTaskSorter implements the default sorting strategy (by weight)
 type DefaultTaskSorter struct{}
 
+func (s *DefaultTaskSorter) Name() string {
+	return SorterWeight
+}
+
 func (s *DefaultTaskSorter) Sort(tasks []model.Task) []model.Task {
 	sortedTasks := make([]model.Task, len(tasks))
 	copy(sortedTasks, tasks)
@@ -25,3 +110,135 @@ func (s *DefaultTaskSorter) Sort(tasks []model.Task) []model.Task {
 
 	return sortedTasks
 }
+
+// ShortestFirstSorter plans the tasks with the smallest estimated duration first
+type ShortestFirstSorter struct{}
+
+func (s *ShortestFirstSorter) Name() string {
+	return SorterShortestFirst
+}
+
+func (s *ShortestFirstSorter) Sort(tasks []model.Task) []model.Task {
+	return sortTasks(tasks, func(a, b model.Task) bool {
+		return a.EstimatedDuration < b.EstimatedDuration
+	})
+}
+
+// PriorityFirstSorter plans the tasks with the highest priority first, tasks
+// of the same priority by weight
+type PriorityFirstSorter struct{}
+
+func (s *PriorityFirstSorter) Name() string {
+	return SorterPriorityFirst
+}
+
+func (s *PriorityFirstSorter) Sort(tasks []model.Task) []model.Task {
+	return sortTasks(tasks, func(a, b model.Task) bool {
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+
+		return weight(a) > weight(b)
+	})
+}
+
+// DeadlineFirstSorter plans the tasks with the earliest deadline first (EDD),
+// tasks without a deadline last and tasks of the same deadline by priority
+type DeadlineFirstSorter struct{}
+
+func (s *DeadlineFirstSorter) Name() string {
+	return SorterDeadlineFirst
+}
+
+func (s *DeadlineFirstSorter) Sort(tasks []model.Task) []model.Task {
+	return sortTasks(tasks, func(a, b model.Task) bool {
+		switch {
+		case a.Deadline == nil && b.Deadline == nil:
+		case a.Deadline == nil:
+			return false
+		case b.Deadline == nil:
+			return true
+		case !a.Deadline.Equal(*b.Deadline):
+			return a.Deadline.Before(*b.Deadline)
+		}
+
+		return a.Priority > b.Priority
+	})
+}
+
+// WSJFSorter plans by weighted shortest job first, the priority divided by
+// the estimated duration, highest first. Tasks without an estimate come
+// first, tasks of the same score by weight.
+type WSJFSorter struct{}
+
+func (s *WSJFSorter) Name() string {
+	return SorterWSJF
+}
+
+func (s *WSJFSorter) Sort(tasks []model.Task) []model.Task {
+	return sortTasks(tasks, func(a, b model.Task) bool {
+		switch {
+		case a.EstimatedDuration <= 0 && b.EstimatedDuration <= 0:
+		case a.EstimatedDuration <= 0:
+			return true
+		case b.EstimatedDuration <= 0:
+			return false
+		default:
+			sa := float64(a.Priority) / a.EstimatedDuration
+			sb := float64(b.Priority) / b.EstimatedDuration
+			if sa != sb {
+				return sa > sb
+			}
+		}
+
+		return weight(a) > weight(b)
+	})
+}
+
+// RandomSorter shuffles the tasks, the same seed gives the same order for the
+// same tasks
+type RandomSorter struct {
+	seed uint64
+}
+
+func NewRandomSorter(seed uint64) *RandomSorter {
+	return &RandomSorter{seed: seed}
+}
+
+func (s *RandomSorter) Name() string {
+	return SorterRandom
+}
+
+func (s *RandomSorter) Seed() uint64 {
+	return s.seed
+}
+
+func (s *RandomSorter) Sort(tasks []model.Task) []model.Task {
+	// start from a fixed order so the result doesn't depend on how the tasks were fetched
+	sortedTasks := sortTasks(tasks, func(a, b model.Task) bool {
+		return a.ID < b.ID
+	})
+
+	random := rand.New(rand.NewPCG(s.seed, s.seed))
+	random.Shuffle(len(sortedTasks), func(i, j int) {
+		sortedTasks[i], sortedTasks[j] = sortedTasks[j], sortedTasks[i]
+	})
+
+	return sortedTasks
+}
+
+func weight(task model.Task) float64 {
+	return task.EstimatedDuration * task.Difficulty
+}
+
+// sortTasks returns a stably sorted copy of the tasks
+func sortTasks(tasks []model.Task, less func(a, b model.Task) bool) []model.Task {
+	sortedTasks := make([]model.Task, len(tasks))
+	copy(sortedTasks, tasks)
+
+	sort.SliceStable(sortedTasks, func(i, j int) bool {
+		return less(sortedTasks[i], sortedTasks[j])
+	})
+
+	return sortedTasks
+}
@@ -1,7 +1,10 @@
 package planner
 
 import (
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"todo-planning/internal/model"
 )
@@ -66,3 +69,81 @@ func TestDefaultTaskSorter_Sort(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskSorters(t *testing.T) {
+	day := func(d int) *time.Time {
+		deadline := time.Date(2024, time.June, d, 0, 0, 0, 0, time.UTC)
+		return &deadline
+	}
+
+	tasks := []model.Task{
+		{ID: 1, EstimatedDuration: 8, Difficulty: 1, Priority: 4, Deadline: day(20)},
+		{ID: 2, EstimatedDuration: 2, Difficulty: 2, Priority: 1},
+		{ID: 3, EstimatedDuration: 4, Difficulty: 3, Priority: 4, Deadline: day(10)},
+		{ID: 4, EstimatedDuration: 0, Difficulty: 1},
+		{ID: 5, EstimatedDuration: 4, Difficulty: 1, Priority: 8, Deadline: day(10)},
+	}
+
+	tests := []struct {
+		name     string
+		expected []uint
+	}{
+		{name: SorterWeight, expected: []uint{3, 1, 2, 5, 4}},
+		{name: SorterShortestFirst, expected: []uint{4, 2, 3, 5, 1}},
+		{name: SorterPriorityFirst, expected: []uint{5, 3, 1, 2, 4}},
+		{name: SorterDeadlineFirst, expected: []uint{5, 3, 1, 2, 4}},
+		{name: SorterWSJF, expected: []uint{4, 5, 3, 1, 2}}, // 1 and 2 score 0.5, 1 weighs more
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorter, err := NewSorter(tt.name, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sorter.Name() != tt.name {
+				t.Errorf("expected name %s, got %s", tt.name, sorter.Name())
+			}
+
+			if ids := sortedIDs(sorter.Sort(tasks)); !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("expected order %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+
+	t.Run(SorterRandom, func(t *testing.T) {
+		first := sortedIDs(NewRandomSorter(7).Sort(tasks))
+
+		reversed := make([]model.Task, len(tasks))
+		for i, task := range tasks {
+			reversed[len(tasks)-1-i] = task
+		}
+		if ids := sortedIDs(NewRandomSorter(7).Sort(reversed)); !reflect.DeepEqual(ids, first) {
+			t.Errorf("expected the same order for the same seed, got %v and %v", first, ids)
+		}
+
+		differs := false
+		for seed := uint64(8); seed < 16 && !differs; seed++ {
+			differs = !reflect.DeepEqual(sortedIDs(NewRandomSorter(seed).Sort(tasks)), first)
+		}
+		if !differs {
+			t.Error("expected other seeds to give another order")
+		}
+	})
+
+	if _, err := NewSorter("unknown", 0); !errors.Is(err, ErrUnknownSorter) {
+		t.Errorf("expected unknown sorter error, got %v", err)
+	}
+
+	if names := Sorters(); len(names) != 6 {
+		t.Errorf("expected 6 registered sorters, got %v", names)
+	}
+}
+
+func sortedIDs(tasks []model.Task) []uint {
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
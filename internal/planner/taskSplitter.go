@@ -0,0 +1,131 @@
+package planner
+
+import (
+	"math"
+	"todo-planning/internal/model"
+)
+
+// SplitParts returns the number of parts a task has to be split into so that
+// every part fits into a regular week of the developer who gets the most
+// work done in a week.
+// It returns 0 when the task fits as it is or nobody can work on it.
+func SplitParts(task model.Task, developers []model.Developer) int {
+	var weeklyEffort float64
+	for _, developer := range developers {
+		if effort := developer.Productivity * WeeklyCapacity(developer); effort > weeklyEffort {
+			weeklyEffort = effort
+		}
+	}
+
+	if weeklyEffort == 0 {
+		return 0
+	}
+
+	parts := int(math.Ceil(CalculateTaskEffort(task)/weeklyEffort - capacityEpsilon))
+	if parts < 2 {
+		return 0
+	}
+
+	return parts
+}
+
+// splitTasks replaces the tasks that don't fit into a week of any developer
+// with their stored sub-tasks, keeping the sub-tasks in order at the position
+// of the parent. The sub-tasks of split tasks that fit into a week again are
+// removed. A preview stores nothing, its sub-tasks get temporary ids
+// following lastID instead.
+func (p *Planner) splitTasks(tasks []model.Task, developers []model.Developer, split map[uint]bool, preview bool, lastID uint) ([]model.Task, []model.TaskSplit, error) {
+	var (
+		result = make([]model.Task, 0, len(tasks))
+		splits []model.TaskSplit
+	)
+
+	for _, task := range tasks {
+		parts := SplitParts(task, developers)
+		if parts == 0 {
+			if split[task.ID] && !preview {
+				if err := p.taskService.UnsplitTask(task); err != nil {
+					return nil, nil, err
+				}
+			}
+
+			result = append(result, task)
+			continue
+		}
+
+		var subTasks []model.Task
+		if preview {
+			subTasks = make([]model.Task, 0, parts)
+			for i := 1; i <= parts; i++ {
+				lastID++
+				subTask := task.Part(i, parts)
+				subTask.ID = lastID
+				subTasks = append(subTasks, subTask)
+			}
+		} else {
+			var err error
+			if subTasks, err = p.taskService.SplitTask(task, parts); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		split := model.TaskSplit{
+			TaskID:     task.ID,
+			TaskName:   task.DisplayName(),
+			Parts:      len(subTasks),
+			SubTaskIDs: make([]uint, 0, len(subTasks)),
+		}
+
+		// every part waits for the one before it
+		for i := range subTasks {
+			split.SubTaskIDs = append(split.SubTaskIDs, subTasks[i].ID)
+			if i > 0 {
+				subTasks[i].DependsOn = []uint{subTasks[i-1].ID}
+			}
+		}
+
+		result = append(result, subTasks...)
+		splits = append(splits, split)
+	}
+
+	return result, splits, nil
+}
+
+// clearTemporaryIDs removes the temporary ids above lastID that the sub-tasks
+// of a preview were planned with
+func clearTemporaryIDs(run *model.PlanRun, lastID uint) {
+	stored := func(ids []uint) []uint {
+		result := make([]uint, 0, len(ids))
+		for _, id := range ids {
+			if id <= lastID {
+				result = append(result, id)
+			}
+		}
+
+		return result
+	}
+
+	clearTask := func(task *model.Task) {
+		if task.ID > lastID {
+			task.ID = 0
+		}
+		if len(task.DependsOn) > 0 {
+			task.DependsOn = stored(task.DependsOn)
+		}
+	}
+
+	for i := range run.Assignments {
+		if run.Assignments[i].TaskID > lastID {
+			run.Assignments[i].TaskID = 0
+		}
+		clearTask(&run.Assignments[i].Task)
+	}
+
+	for i := range run.Unassigned {
+		clearTask(&run.Unassigned[i].Task)
+	}
+
+	for i := range run.Splits {
+		run.Splits[i].SubTaskIDs = stored(run.Splits[i].SubTaskIDs)
+	}
+}
@@ -0,0 +1,61 @@
+package planner
+
+import (
+	"testing"
+
+	"todo-planning/internal/model"
+)
+
+func TestSplitParts(t *testing.T) {
+	tests := []struct {
+		name       string
+		task       model.Task
+		developers []model.Developer
+		expected   int
+	}{
+		{
+			name:       "task fits into a week",
+			task:       model.Task{Difficulty: 2, EstimatedDuration: 20},
+			developers: []model.Developer{{Productivity: 1}},
+			expected:   0,
+		},
+		{
+			name:       "task fits exactly into a week",
+			task:       model.Task{Difficulty: 1, EstimatedDuration: 45},
+			developers: []model.Developer{{Productivity: 1}},
+			expected:   0,
+		},
+		{
+			name:       "split by the most productive developer",
+			task:       model.Task{Difficulty: 4, EstimatedDuration: 50},
+			developers: []model.Developer{{Productivity: 1}, {Productivity: 2}},
+			expected:   3,
+		},
+		{
+			name:       "exact multiple of a week",
+			task:       model.Task{Difficulty: 1, EstimatedDuration: 90},
+			developers: []model.Developer{{Productivity: 1}},
+			expected:   2,
+		},
+		{
+			name:       "zero productivity",
+			task:       model.Task{Difficulty: 1, EstimatedDuration: 90},
+			developers: []model.Developer{{Productivity: 0}},
+			expected:   0,
+		},
+		{
+			name:       "no developers",
+			task:       model.Task{Difficulty: 1, EstimatedDuration: 90},
+			developers: []model.Developer{},
+			expected:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if parts := SplitParts(tt.task, tt.developers); parts != tt.expected {
+				t.Errorf("expected %d parts, got %d", tt.expected, parts)
+			}
+		})
+	}
+}
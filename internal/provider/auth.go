@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Auth types
+const (
+	AuthNone   = ""
+	AuthBearer = "bearer"
+	AuthBasic  = "basic"
+	AuthAPIKey = "api_key"
+)
+
+// defaultAPIKeyHeader carries the key of api_key auth when no header is configured
+const defaultAPIKeyHeader = "X-API-Key"
+
+// AuthConfig holds the credentials of a provider. Secrets are read from
+// environment variables or files so they don't end up in config.yaml.
+type AuthConfig struct {
+	Type     string `yaml:"type"`     // bearer, basic or api_key, no auth when empty
+	Token    Secret `yaml:"token"`    // bearer token
+	Username string `yaml:"username"` // basic auth user
+	Password Secret `yaml:"password"` // basic auth password
+	Header   string `yaml:"header"`   // api_key header, X-API-Key by default
+	Key      Secret `yaml:"key"`      // api_key value
+}
+
+// Secret is read from an environment variable or a file
+type Secret struct {
+	Env  string `yaml:"env"`
+	File string `yaml:"file"` // surrounding whitespace is trimmed
+}
+
+// Resolve reads the secret
+func (s Secret) Resolve() (string, error) {
+	switch {
+	case s.Env != "" && s.File != "":
+		return "", fmt.Errorf("secret can't be read from both env %s and file %s", s.Env, s.File)
+	case s.Env != "":
+		value := os.Getenv(s.Env)
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+
+		return value, nil
+	case s.File != "":
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return "", fmt.Errorf("secret file %s is empty", s.File)
+		}
+
+		return value, nil
+	default:
+		return "", fmt.Errorf("secret needs an env or a file")
+	}
+}
+
+// Auth adds credentials to the requests of a provider
+type Auth interface {
+	Apply(req *http.Request)
+}
+
+// NewAuth reads the secrets of an auth config, it returns nil without auth
+func NewAuth(config AuthConfig) (Auth, error) {
+	switch config.Type {
+	case AuthNone:
+		return nil, nil
+	case AuthBearer:
+		token, err := config.Token.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("bearer auth token: %w", err)
+		}
+
+		return headerAuth{header: "Authorization", value: "Bearer " + token}, nil
+	case AuthBasic:
+		if config.Username == "" {
+			return nil, fmt.Errorf("basic auth needs a username")
+		}
+
+		password, err := config.Password.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("basic auth password: %w", err)
+		}
+
+		return basicAuth{username: config.Username, password: password}, nil
+	case AuthAPIKey:
+		key, err := config.Key.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("api key: %w", err)
+		}
+
+		return headerAuth{header: withDefault(config.Header, defaultAPIKeyHeader), value: key}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth type %q", config.Type)
+	}
+}
+
+// headerAuth sends the credentials in a header
+type headerAuth struct {
+	header string
+	value  string
+}
+
+func (a headerAuth) Apply(req *http.Request) {
+	req.Header.Set(a.header, a.value)
+}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (a basicAuth) Apply(req *http.Request) {
+	req.SetBasicAuth(a.username, a.password)
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewAuth(t *testing.T) {
+	t.Setenv("TEST_PROVIDER_TOKEN", "env-token")
+
+	secretFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secretFile, []byte("file-password\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		config     AuthConfig
+		wantHeader string
+		wantValue  string
+		wantErr    bool
+	}{
+		{
+			name:       "bearer token from env",
+			config:     AuthConfig{Type: AuthBearer, Token: Secret{Env: "TEST_PROVIDER_TOKEN"}},
+			wantHeader: "Authorization",
+			wantValue:  "Bearer env-token",
+		},
+		{
+			name:       "basic auth with password from file",
+			config:     AuthConfig{Type: AuthBasic, Username: "planner", Password: Secret{File: secretFile}},
+			wantHeader: "Authorization",
+			wantValue:  "Basic cGxhbm5lcjpmaWxlLXBhc3N3b3Jk",
+		},
+		{
+			name:       "api key in default header",
+			config:     AuthConfig{Type: AuthAPIKey, Key: Secret{Env: "TEST_PROVIDER_TOKEN"}},
+			wantHeader: "X-API-Key",
+			wantValue:  "env-token",
+		},
+		{
+			name:       "api key in custom header",
+			config:     AuthConfig{Type: AuthAPIKey, Header: "X-Tracker-Key", Key: Secret{File: secretFile}},
+			wantHeader: "X-Tracker-Key",
+			wantValue:  "file-password",
+		},
+		{name: "unset env", config: AuthConfig{Type: AuthBearer, Token: Secret{Env: "TEST_PROVIDER_MISSING"}}, wantErr: true},
+		{name: "missing file", config: AuthConfig{Type: AuthBearer, Token: Secret{File: secretFile + ".missing"}}, wantErr: true},
+		{name: "env and file", config: AuthConfig{Type: AuthBearer, Token: Secret{Env: "TEST_PROVIDER_TOKEN", File: secretFile}}, wantErr: true},
+		{name: "no secret", config: AuthConfig{Type: AuthAPIKey}, wantErr: true},
+		{name: "basic auth without username", config: AuthConfig{Type: AuthBasic, Password: Secret{File: secretFile}}, wantErr: true},
+		{name: "unknown type", config: AuthConfig{Type: "oauth"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := NewAuth(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "http://tracker", nil)
+			auth.Apply(req)
+
+			if got := req.Header.Get(tt.wantHeader); got != tt.wantValue {
+				t.Errorf("Expected %s header %q, got %q", tt.wantHeader, tt.wantValue, got)
+			}
+		})
+	}
+}
+
+func TestGenericClient_Auth(t *testing.T) {
+	t.Setenv("TEST_PROVIDER_TOKEN", "env-token")
+
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if len(authorizations) == 1 {
+			// the retry has to be authenticated as well
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`[{"id": "1", "level": 1, "hours": 2}]`))
+	}))
+	defer server.Close()
+
+	p, err := New("generic", "tracker", Options{
+		"url":    server.URL,
+		"fields": map[string]any{"id": "$.id", "difficulty": "$.level", "duration": "$.hours"},
+		"http":   map[string]any{"retries": 1, "base_delay": "1ms"},
+		"auth":   map[string]any{"type": "bearer", "token": map[string]any{"env": "TEST_PROVIDER_TOKEN"}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := p.FetchTasks(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(authorizations) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(authorizations))
+	}
+	for i, authorization := range authorizations {
+		if authorization != "Bearer env-token" {
+			t.Errorf("Expected request %d to send the bearer token, got %q", i+1, authorization)
+		}
+	}
+
+	if _, err := New("mock-one", "tracker", Options{
+		"url":  server.URL,
+		"auth": map[string]any{"type": "bearer", "token": map[string]any{"env": "TEST_PROVIDER_MISSING"}},
+	}); err == nil {
+		t.Error("Expected an error for a token that isn't set")
+	}
+}
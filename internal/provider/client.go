@@ -1,6 +1,9 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+
 	"todo-planning/internal/model"
 )
 
@@ -8,3 +11,51 @@ import (
 type Provider interface {
 	FetchTasks() ([]model.Task, error)
 }
+
+// ContextProvider is implemented by providers that stop fetching when the
+// context is cancelled
+type ContextProvider interface {
+	FetchTasksContext(ctx context.Context) ([]model.Task, error)
+}
+
+// Fetch fetches the tasks of a provider within the context. Providers that
+// don't take a context are left running in the background when the context
+// ends first.
+func Fetch(ctx context.Context, p Provider) ([]model.Task, error) {
+	if cp, ok := p.(ContextProvider); ok {
+		return cp.FetchTasksContext(ctx)
+	}
+
+	type fetchResult struct {
+		tasks []model.Task
+		err   error
+	}
+
+	done := make(chan fetchResult, 1)
+	go func() {
+		tasks, err := p.FetchTasks()
+		done <- fetchResult{tasks: tasks, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.tasks, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Named is implemented by providers that report a name, used in sync reports
+// and as the source of the tasks they fetch
+type Named interface {
+	Name() string
+}
+
+// NameOf returns the name of a provider, or its position when it has none
+func NameOf(p Provider, index int) string {
+	if named, ok := p.(Named); ok {
+		return named.Name()
+	}
+
+	return fmt.Sprintf("provider-%d", index+1)
+}
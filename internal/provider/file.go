@@ -0,0 +1,378 @@
+package provider
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-planning/internal/model"
+	"todo-planning/internal/utility"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// FileConfig describes a CSV, JSON or YAML file of tasks
+type FileConfig struct {
+	Name    string       `yaml:"name"` // also the source of the tasks
+	Path    string       `yaml:"path"`
+	Format  string       `yaml:"format"` // csv, json or yaml, taken from the file extension when empty
+	Columns FileColumns  `yaml:"columns"`
+	Units   GenericUnits `yaml:"units"`
+}
+
+// FileColumns maps the columns of a CSV file, or the keys of the records of a
+// JSON or YAML file, onto the task fields
+type FileColumns struct {
+	ID         string `yaml:"id"`         // "id" by default
+	Name       string `yaml:"name"`       // "name" by default, optional
+	Difficulty string `yaml:"difficulty"` // "difficulty" by default
+	Duration   string `yaml:"duration"`   // "duration" by default
+}
+
+// RowError is a row of a file that can't be imported. Rows of a CSV file are
+// numbered like lines with the header as row 1, the records of JSON and YAML
+// files from 1.
+type RowError struct {
+	Row     int
+	Message string
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+// RowErrors are the rows of a file that can't be imported
+type RowErrors []RowError
+
+func (e RowErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, rowError := range e {
+		messages[i] = rowError.Error()
+	}
+
+	return fmt.Sprintf("%d invalid rows: %s", len(e), strings.Join(messages, "; "))
+}
+
+// FileClient reads tasks from a file, e.g. a backlog exported from a spreadsheet
+type FileClient struct {
+	name            string
+	path            string
+	format          string
+	columns         FileColumns
+	hoursPerUnit    float64
+	difficultyScale float64
+}
+
+func NewFileClient(config FileConfig) (*FileClient, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("file provider needs a name")
+	}
+
+	if config.Name == model.SourceManual {
+		return nil, fmt.Errorf("file provider can't use source %s, it is kept for tasks entered through the API", model.SourceManual)
+	}
+
+	if config.Path == "" {
+		return nil, fmt.Errorf("file provider %s needs a path", config.Name)
+	}
+
+	format := strings.ToLower(config.Format)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(config.Path)), ".")
+	}
+	if format == "yml" {
+		format = FormatYAML
+	}
+	if format != FormatCSV && format != FormatJSON && format != FormatYAML {
+		return nil, fmt.Errorf("file provider %s: unknown format %q, expected csv, json or yaml", config.Name, format)
+	}
+
+	client := &FileClient{
+		name:   config.Name,
+		path:   config.Path,
+		format: format,
+		columns: FileColumns{
+			ID:         withDefault(config.Columns.ID, "id"),
+			Name:       withDefault(config.Columns.Name, "name"),
+			Difficulty: withDefault(config.Columns.Difficulty, "difficulty"),
+			Duration:   withDefault(config.Columns.Duration, "duration"),
+		},
+		difficultyScale: 1,
+	}
+
+	if config.Units.DifficultyScale != 0 {
+		client.difficultyScale = config.Units.DifficultyScale
+	}
+
+	var err error
+	if client.hoursPerUnit, err = hoursPerUnit(config.Units); err != nil {
+		return nil, fmt.Errorf("file provider %s: %w", config.Name, err)
+	}
+
+	return client, nil
+}
+
+func (fc *FileClient) Name() string {
+	return fc.name
+}
+
+// FetchTasks reads the tasks of the file. It fails when any row is invalid,
+// so a broken file doesn't make the tasks of its invalid rows look removed.
+func (fc *FileClient) FetchTasks() ([]model.Task, error) {
+	tasks, rowErrors, err := fc.ReadTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, rowErrors
+	}
+
+	return tasks, nil
+}
+
+// ReadTasks reads the file and returns the tasks of the valid rows together
+// with the errors of the invalid ones
+func (fc *FileClient) ReadTasks() ([]model.Task, RowErrors, error) {
+	file, err := os.Open(fc.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", fc.path, err)
+	}
+	defer file.Close()
+
+	return fc.ParseTasks(file)
+}
+
+// ParseTasks reads tasks in the format of the file
+func (fc *FileClient) ParseTasks(r io.Reader) ([]model.Task, RowErrors, error) {
+	var records []fileRecord
+	var err error
+
+	switch fc.format {
+	case FormatCSV:
+		records, err = fc.readCSV(r)
+	default:
+		records, err = fc.readDocument(r)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	tasks := make([]model.Task, 0, len(records))
+	rows := make(map[string]int, len(records))
+	var rowErrors RowErrors
+
+	for _, record := range records {
+		task, err := fc.toTask(record)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: record.row, Message: err.Error()})
+			continue
+		}
+
+		if row, ok := rows[task.ExternalID]; ok {
+			rowErrors = append(rowErrors, RowError{
+				Row:     record.row,
+				Message: fmt.Sprintf("duplicate id %q, already used in row %d", task.ExternalID, row),
+			})
+			continue
+		}
+		rows[task.ExternalID] = record.row
+
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rowErrors, nil
+}
+
+// fileRecord is a row of a file with its values by column
+type fileRecord struct {
+	row    int
+	values map[string]any
+}
+
+func (fc *FileClient) readCSV(r io.Reader) ([]fileRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	for _, column := range []string{fc.columns.ID, fc.columns.Difficulty, fc.columns.Duration} {
+		if !containsFold(header, column) {
+			return nil, fmt.Errorf("csv header has no %q column", column)
+		}
+	}
+
+	var records []fileRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := fileRecord{row: line, values: make(map[string]any, len(header))}
+		blank := true
+		for i, field := range fields {
+			if i < len(header) {
+				record.values[strings.ToLower(header[i])] = field
+			}
+			if strings.TrimSpace(field) != "" {
+				blank = false
+			}
+		}
+
+		if !blank {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+// readDocument reads the list of records of a JSON or YAML file
+func (fc *FileClient) readDocument(r io.Reader) ([]fileRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]any
+	if fc.format == FormatJSON {
+		err = json.Unmarshal(data, &items)
+	} else {
+		err = yaml.Unmarshal(data, &items)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s, expected a list of tasks: %w", fc.format, err)
+	}
+
+	records := make([]fileRecord, len(items))
+	for i, item := range items {
+		values := make(map[string]any, len(item))
+		for key, value := range item {
+			values[strings.ToLower(key)] = value
+		}
+
+		records[i] = fileRecord{row: i + 1, values: values}
+	}
+
+	return records, nil
+}
+
+func (fc *FileClient) toTask(record fileRecord) (model.Task, error) {
+	task := model.Task{Source: fc.name}
+
+	id, ok := record.value(fc.columns.ID)
+	if !ok {
+		return task, fmt.Errorf("missing %s", fc.columns.ID)
+	}
+	task.ExternalID = id
+
+	if name, ok := record.value(fc.columns.Name); ok {
+		task.Name = utility.ToPointer(name)
+	}
+
+	difficulty, err := record.number(fc.columns.Difficulty)
+	if err != nil {
+		return task, err
+	}
+	task.Difficulty = difficulty * fc.difficultyScale
+
+	duration, err := record.number(fc.columns.Duration)
+	if err != nil {
+		return task, err
+	}
+	task.EstimatedDuration = duration * fc.hoursPerUnit
+
+	return task, nil
+}
+
+// value returns the trimmed value of a column, false when it is missing or empty
+func (r fileRecord) value(column string) (string, bool) {
+	value, ok := r.values[strings.ToLower(column)]
+	if !ok || value == nil {
+		return "", false
+	}
+
+	text := strings.TrimSpace(stringValue(value))
+
+	return text, text != ""
+}
+
+// number returns the non-negative number in a column
+func (r fileRecord) number(column string) (float64, error) {
+	value, ok := r.values[strings.ToLower(column)]
+	if !ok || value == nil {
+		return 0, fmt.Errorf("missing %s", column)
+	}
+
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	default:
+		text := strings.TrimSpace(stringValue(v))
+		if text == "" {
+			return 0, fmt.Errorf("missing %s", column)
+		}
+
+		var err error
+		if number, err = strconv.ParseFloat(text, 64); err != nil {
+			return 0, fmt.Errorf("%s %q is not a number", column, text)
+		}
+	}
+
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("%s %v is not a number", column, number)
+	}
+
+	if number < 0 {
+		return 0, fmt.Errorf("%s can't be negative, got %v", column, number)
+	}
+
+	return number, nil
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileClient_ParseTasks(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        FileConfig
+		content       string
+		wantIDs       []string
+		wantDurations []float64
+		wantRows      []int
+		wantErr       bool
+	}{
+		{
+			name:   "csv with column mapping",
+			config: FileConfig{Format: FormatCSV, Columns: FileColumns{ID: "Key", Name: "Summary", Difficulty: "Points", Duration: "Days"}, Units: GenericUnits{Duration: "days"}},
+			content: "Key,Summary,Points,Days\n" +
+				"A-1,First task,3,1\n" +
+				"A-2, Second task ,5,0.5\n" +
+				",,,\n" +
+				"A-3,Third task,2,2\n",
+			wantIDs:       []string{"A-1", "A-2", "A-3"},
+			wantDurations: []float64{8, 4, 16},
+		},
+		{
+			name:   "csv with invalid rows",
+			config: FileConfig{Format: FormatCSV},
+			content: "id,name,difficulty,duration\n" +
+				"1,Valid,1,2\n" +
+				"2,Negative difficulty,-1,2\n" +
+				"3,Not a number,1,two\n" +
+				",Missing id,1,2\n" +
+				"1,Duplicate id,1,2\n" +
+				"4,Missing duration,1,\n",
+			wantIDs:       []string{"1"},
+			wantDurations: []float64{2},
+			wantRows:      []int{3, 4, 5, 6, 7},
+		},
+		{
+			name:    "csv without a required column",
+			config:  FileConfig{Format: FormatCSV},
+			content: "id,name,difficulty\n1,Task,1\n",
+			wantErr: true,
+		},
+		{
+			name:          "json",
+			config:        FileConfig{Format: FormatJSON},
+			content:       `[{"id": 1, "name": "Task 1", "difficulty": 2, "duration": 3}, {"ID": "2", "Difficulty": "4", "Duration": 5}]`,
+			wantIDs:       []string{"1", "2"},
+			wantDurations: []float64{3, 5},
+		},
+		{
+			name:          "yaml",
+			config:        FileConfig{Format: FormatYAML},
+			content:       "- id: T-1\n  difficulty: 2\n  duration: 3\n- id: T-2\n  difficulty: -1\n  duration: 1\n",
+			wantIDs:       []string{"T-1"},
+			wantDurations: []float64{3},
+			wantRows:      []int{2},
+		},
+		{
+			name:    "json that isn't a list",
+			config:  FileConfig{Format: FormatJSON},
+			content: `{"id": 1}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Name = "spreadsheet"
+			tt.config.Path = "tasks." + tt.config.Format
+			client, err := NewFileClient(tt.config)
+			if err != nil {
+				t.Fatalf("NewFileClient() error = %v", err)
+			}
+
+			tasks, rowErrors, err := client.ParseTasks(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(tasks) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d tasks, got %d: %v", len(tt.wantIDs), len(tasks), rowErrors)
+			}
+			for i, task := range tasks {
+				if task.ExternalID != tt.wantIDs[i] || task.EstimatedDuration != tt.wantDurations[i] {
+					t.Errorf("Expected task %s with duration %v, got %s with %v", tt.wantIDs[i], tt.wantDurations[i], task.ExternalID, task.EstimatedDuration)
+				}
+				if task.Source != "spreadsheet" {
+					t.Errorf("Expected source 'spreadsheet', got '%s'", task.Source)
+				}
+			}
+
+			if len(rowErrors) != len(tt.wantRows) {
+				t.Fatalf("Expected errors in rows %v, got %v", tt.wantRows, rowErrors)
+			}
+			for i, rowError := range rowErrors {
+				if rowError.Row != tt.wantRows[i] {
+					t.Errorf("Expected error in row %d, got %v", tt.wantRows[i], rowError)
+				}
+			}
+		})
+	}
+}
+
+func TestFileClient_FetchTasks(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "tasks.csv")
+	if err := os.WriteFile(valid, []byte("id,name,difficulty,duration\n1,Task 1,1,2\n2,Task 2,3,4\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	p, err := New("file", "spreadsheet", Options{"path": valid})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tasks, err := p.FetchTasks()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tasks) != 2 || tasks[1].Name == nil || *tasks[1].Name != "Task 2" {
+		t.Errorf("Expected 2 tasks from the file, got %+v", tasks)
+	}
+
+	// a file with an invalid row fails as a whole
+	invalid := filepath.Join(dir, "invalid.yml")
+	if err := os.WriteFile(invalid, []byte("- id: 1\n  difficulty: 1\n  duration: -2\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	client, err := NewFileClient(FileConfig{Name: "spreadsheet", Path: invalid})
+	if err != nil {
+		t.Fatalf("NewFileClient() error = %v", err)
+	}
+
+	var rowErrors RowErrors
+	if _, err := client.FetchTasks(); !errors.As(err, &rowErrors) || len(rowErrors) != 1 {
+		t.Errorf("Expected 1 row error, got %v", err)
+	}
+}
+
+func TestNewFileClient_InvalidConfig(t *testing.T) {
+	configs := []FileConfig{
+		{Path: "tasks.csv"},
+		{Name: "manual", Path: "tasks.csv"},
+		{Name: "spreadsheet"},
+		{Name: "spreadsheet", Path: "tasks.xlsx"},
+		{Name: "spreadsheet", Path: "tasks.csv", Units: GenericUnits{Duration: "sprints"}},
+	}
+
+	for _, config := range configs {
+		if _, err := NewFileClient(config); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Formula is an arithmetic expression over named values, e.g.
+// "ceil(story_points * 4) + timeoriginalestimate / 3600". It supports
+// numbers, names, + - * /, parentheses and the functions min, max, ceil,
+// floor and round.
+type Formula struct {
+	expression string
+	root       formulaNode
+	names      []string
+}
+
+type formulaNode interface {
+	eval(values func(name string) (float64, error)) (float64, error)
+}
+
+type formulaNumber float64
+
+type formulaName string
+
+type formulaUnary struct {
+	operand formulaNode
+}
+
+type formulaBinary struct {
+	operator    byte
+	left, right formulaNode
+}
+
+type formulaCall struct {
+	function string
+	args     []formulaNode
+}
+
+// formulaFunctions are the functions a formula can call with their number of
+// arguments, -1 for any number of at least one
+var formulaFunctions = map[string]int{
+	"min":   -1,
+	"max":   -1,
+	"ceil":  1,
+	"floor": 1,
+	"round": 1,
+}
+
+// ParseFormula parses an arithmetic expression
+func ParseFormula(expression string) (*Formula, error) {
+	p := &formulaParser{input: expression}
+	p.skipSpace()
+
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, fmt.Errorf("invalid formula %q: %w", expression, err)
+	}
+
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("invalid formula %q: unexpected %q at %d", expression, p.input[p.pos], p.pos)
+	}
+
+	return &Formula{expression: expression, root: root, names: p.names}, nil
+}
+
+func (f *Formula) String() string {
+	return f.expression
+}
+
+// Names returns the names the formula refers to in order of appearance
+func (f *Formula) Names() []string {
+	return f.names
+}
+
+// Eval computes the formula, looking up the named values with values
+func (f *Formula) Eval(values func(name string) (float64, error)) (float64, error) {
+	result, err := f.root.eval(values)
+	if err != nil {
+		return 0, err
+	}
+
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, fmt.Errorf("formula %q is not a number", f.expression)
+	}
+
+	return result, nil
+}
+
+func (n formulaNumber) eval(func(string) (float64, error)) (float64, error) {
+	return float64(n), nil
+}
+
+func (n formulaName) eval(values func(string) (float64, error)) (float64, error) {
+	return values(string(n))
+}
+
+func (n formulaUnary) eval(values func(string) (float64, error)) (float64, error) {
+	value, err := n.operand.eval(values)
+	return -value, err
+}
+
+func (n formulaBinary) eval(values func(string) (float64, error)) (float64, error) {
+	left, err := n.left.eval(values)
+	if err != nil {
+		return 0, err
+	}
+
+	right, err := n.right.eval(values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.operator {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	default:
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+
+		return left / right, nil
+	}
+}
+
+func (n formulaCall) eval(values func(string) (float64, error)) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(values)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = value
+	}
+
+	switch n.function {
+	case "min":
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = math.Min(result, arg)
+		}
+		return result, nil
+	case "max":
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = math.Max(result, arg)
+		}
+		return result, nil
+	case "ceil":
+		return math.Ceil(args[0]), nil
+	case "floor":
+		return math.Floor(args[0]), nil
+	default:
+		return math.Round(args[0]), nil
+	}
+}
+
+// formulaParser is a recursive descent parser of formulas
+type formulaParser struct {
+	input string
+	pos   int
+	names []string
+}
+
+// parseSum parses terms joined by + and -
+func (p *formulaParser) parseSum() (formulaNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == '+' || p.peek() == '-' {
+		operator := p.next()
+
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{operator: operator, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseProduct parses factors joined by * and /
+func (p *formulaParser) parseProduct() (formulaNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == '*' || p.peek() == '/' {
+		operator := p.next()
+
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{operator: operator, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseFactor parses a number, a name, a call, a negation or a parenthesized sum
+func (p *formulaParser) parseFactor() (formulaNode, error) {
+	switch c := p.peek(); {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end")
+	case c == '-':
+		p.next()
+
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		return formulaUnary{operand: operand}, nil
+	case c == '(':
+		p.next()
+
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ) at %d", p.pos)
+		}
+		p.next()
+
+		return node, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case c == '_' || unicode.IsLetter(rune(c)):
+		return p.parseName()
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", c, p.pos)
+	}
+}
+
+func (p *formulaParser) parseNumber() (formulaNode, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] == '.' || (p.input[p.pos] >= '0' && p.input[p.pos] <= '9')) {
+		p.pos++
+	}
+
+	value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q at %d", p.input[start:p.pos], start)
+	}
+	p.skipSpace()
+
+	return formulaNumber(value), nil
+}
+
+func (p *formulaParser) parseName() (formulaNode, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isNameChar(p.input[p.pos]) {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+	p.skipSpace()
+
+	if p.peek() != '(' {
+		p.names = append(p.names, name)
+		return formulaName(name), nil
+	}
+
+	function := strings.ToLower(name)
+	arity, ok := formulaFunctions[function]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	p.next()
+
+	var args []formulaNode
+	for p.peek() != ')' {
+		if len(args) > 0 {
+			if p.peek() != ',' {
+				return nil, fmt.Errorf("expected , or ) at %d", p.pos)
+			}
+			p.next()
+		}
+
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next()
+
+	if len(args) == 0 || (arity > 0 && len(args) != arity) {
+		return nil, fmt.Errorf("wrong number of arguments for %s", name)
+	}
+
+	return formulaCall{function: function, args: args}, nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || unicode.IsLetter(rune(c))
+}
+
+// peek returns the next character, 0 at the end
+func (p *formulaParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+// next consumes the next character and the whitespace after it
+func (p *formulaParser) next() byte {
+	c := p.input[p.pos]
+	p.pos++
+	p.skipSpace()
+
+	return c
+}
+
+func (p *formulaParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
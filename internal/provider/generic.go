@@ -0,0 +1,356 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-planning/internal/model"
+	"todo-planning/internal/utility"
+)
+
+// GenericConfig describes a JSON feed and how its items map onto tasks
+type GenericConfig struct {
+	Name        string            `yaml:"name"` // also the source of the tasks
+	Url         string            `yaml:"url"`
+	Items       string            `yaml:"items"` // path to the list of tasks, "$" when the response is the list
+	Fields      GenericFields     `yaml:"fields"`
+	Units       GenericUnits      `yaml:"units"`
+	HTTP        HTTPConfig        `yaml:"http"` // retries and circuit breaker, DefaultHTTPConfig for the settings left unset
+	Auth        AuthConfig        `yaml:"auth"`
+	Pagination  PaginationConfig  `yaml:"pagination"`
+	Incremental IncrementalConfig `yaml:"incremental"`
+}
+
+// GenericFields holds the paths of the task fields within an item
+type GenericFields struct {
+	ID         string `yaml:"id"`
+	Name       string `yaml:"name"` // optional
+	Difficulty string `yaml:"difficulty"`
+	Duration   string `yaml:"duration"`
+}
+
+// GenericUnits converts the difficulty and duration of a feed
+type GenericUnits struct {
+	Duration        string  `yaml:"duration"`         // seconds, minutes, hours (default), days or weeks
+	HoursPerDay     float64 `yaml:"hours_per_day"`    // length of a working day, 8 by default
+	DifficultyScale float64 `yaml:"difficulty_scale"` // multiplier for the difficulty, 1 by default
+}
+
+// defaultHoursPerDay is the length of a working day used to convert days and weeks
+const defaultHoursPerDay = 8
+
+// GenericClient fetches tasks from any JSON feed described by a GenericConfig
+type GenericClient struct {
+	name            string
+	url             string
+	items           *JSONPath
+	id              *JSONPath
+	taskName        *JSONPath
+	difficulty      *JSONPath
+	duration        *JSONPath
+	hoursPerUnit    float64
+	difficultyScale float64
+	client          *HTTPClient
+	pager           *pager
+	incremental     IncrementalConfig
+}
+
+func NewGenericClient(config GenericConfig) (*GenericClient, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("generic provider needs a name")
+	}
+
+	if config.Url == "" {
+		return nil, fmt.Errorf("generic provider %s needs a url", config.Name)
+	}
+
+	client := &GenericClient{
+		name:            config.Name,
+		url:             config.Url,
+		difficultyScale: 1,
+		incremental:     config.Incremental,
+	}
+
+	var err error
+	if client.client, err = newProviderClient(config.HTTP.withDefaults(), config.Auth); err != nil {
+		return nil, fmt.Errorf("generic provider %s: %w", config.Name, err)
+	}
+
+	if client.pager, err = newPager(config.Pagination); err != nil {
+		return nil, fmt.Errorf("generic provider %s: %w", config.Name, err)
+	}
+
+	if config.Units.DifficultyScale != 0 {
+		client.difficultyScale = config.Units.DifficultyScale
+	}
+
+	if client.hoursPerUnit, err = hoursPerUnit(config.Units); err != nil {
+		return nil, fmt.Errorf("generic provider %s: %w", config.Name, err)
+	}
+
+	items := config.Items
+	if items == "" {
+		items = "$"
+	}
+
+	paths := []struct {
+		target     **JSONPath
+		expression string
+		field      string
+		required   bool
+	}{
+		{&client.items, items, "items", true},
+		{&client.id, config.Fields.ID, "id", true},
+		{&client.taskName, config.Fields.Name, "name", false},
+		{&client.difficulty, config.Fields.Difficulty, "difficulty", true},
+		{&client.duration, config.Fields.Duration, "duration", true},
+	}
+
+	for _, path := range paths {
+		if path.expression == "" {
+			if path.required {
+				return nil, fmt.Errorf("generic provider %s needs a %s field mapping", config.Name, path.field)
+			}
+
+			continue
+		}
+
+		if *path.target, err = ParseJSONPath(path.expression); err != nil {
+			return nil, fmt.Errorf("generic provider %s, field %s: %w", config.Name, path.field, err)
+		}
+	}
+
+	return client, nil
+}
+
+func hoursPerUnit(units GenericUnits) (float64, error) {
+	hoursPerDay := units.HoursPerDay
+	if hoursPerDay == 0 {
+		hoursPerDay = defaultHoursPerDay
+	}
+
+	switch strings.ToLower(units.Duration) {
+	case "seconds", "second", "s":
+		return 1.0 / 3600, nil
+	case "minutes", "minute", "m":
+		return 1.0 / 60, nil
+	case "", "hours", "hour", "h":
+		return 1, nil
+	case "days", "day", "d":
+		return hoursPerDay, nil
+	case "weeks", "week", "w":
+		return hoursPerDay * 5, nil
+	default:
+		return 0, fmt.Errorf("unknown duration unit %q", units.Duration)
+	}
+}
+
+func (gc *GenericClient) Name() string {
+	return gc.name
+}
+
+func (gc *GenericClient) FetchTasks() ([]model.Task, error) {
+	return gc.FetchTasksContext(context.Background())
+}
+
+func (gc *GenericClient) FetchTasksContext(ctx context.Context) ([]model.Task, error) {
+	return collect(ctx, gc)
+}
+
+// StreamTasks hands the tasks to handle page by page
+func (gc *GenericClient) StreamTasks(ctx context.Context, handle func(tasks []model.Task) error) error {
+	_, err := gc.StreamChanges(ctx, Cursor{}, handle)
+	return err
+}
+
+// StreamChanges hands the tasks changed since the cursor to handle page by page
+func (gc *GenericClient) StreamChanges(ctx context.Context, cursor Cursor, handle func(tasks []model.Task) error) (Changes, error) {
+	return fetchChanges(ctx, gc.client, gc.pager, gc.url, gc.incremental, cursor, func(body []byte) (int, error) {
+		tasks, err := gc.ParseTasks(body)
+		if err != nil {
+			return 0, err
+		}
+
+		return len(tasks), handle(tasks)
+	})
+}
+
+// ParseTasks maps a response body onto tasks
+func (gc *GenericClient) ParseTasks(body []byte) ([]model.Task, error) {
+	document, err := decodeDocument(body)
+	if err != nil {
+		return nil, err
+	}
+
+	items := gc.items.Select(document)
+	if len(items) == 1 {
+		// a path pointing at the list itself selects its elements
+		if list, ok := items[0].([]any); ok {
+			items = list
+		} else if items[0] == nil {
+			// an empty page may come with a null list
+			items = nil
+		}
+	}
+
+	now := time.Now()
+	tasks := make([]model.Task, 0, len(items))
+	for i, item := range items {
+		task, err := gc.toTask(item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// ParseWebhook reads pushed tasks, either a document like a response of the
+// feed or items of the feed on their own. The items of delete events only
+// need an id.
+func (gc *GenericClient) ParseWebhook(event string, body []byte) ([]model.Task, error) {
+	document, err := decodeDocument(body)
+	if err != nil {
+		return nil, err
+	}
+
+	items := gc.items.Select(document)
+	if len(items) == 1 {
+		if list, ok := items[0].([]any); ok {
+			items = list
+		}
+	}
+	if len(items) == 0 {
+		// the payload holds items without the surrounding document
+		if list, ok := document.([]any); ok {
+			items = list
+		} else {
+			items = []any{document}
+		}
+	}
+
+	now := time.Now()
+	tasks := make([]model.Task, 0, len(items))
+	for i, item := range items {
+		var task model.Task
+		var err error
+		if event == EventDelete {
+			task, err = gc.toDeletedTask(item)
+		} else {
+			task, err = gc.toTask(item)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// toDeletedTask reads only the id of an item
+func (gc *GenericClient) toDeletedTask(item any) (model.Task, error) {
+	id, ok := gc.id.Lookup(item)
+	if !ok {
+		return model.Task{}, fmt.Errorf("missing id at %s", gc.id)
+	}
+
+	return model.Task{ExternalID: stringValue(id), Source: gc.name}, nil
+}
+
+func (gc *GenericClient) toTask(item any) (model.Task, error) {
+	task := model.Task{Source: gc.name}
+
+	id, ok := gc.id.Lookup(item)
+	if !ok {
+		return task, fmt.Errorf("missing id at %s", gc.id)
+	}
+	task.ExternalID = stringValue(id)
+
+	if gc.taskName != nil {
+		if name, ok := gc.taskName.Lookup(item); ok {
+			task.Name = utility.ToPointer(stringValue(name))
+		}
+	}
+
+	difficulty, err := numberAt(gc.difficulty, item)
+	if err != nil {
+		return task, err
+	}
+	task.Difficulty = difficulty * gc.difficultyScale
+
+	duration, err := numberAt(gc.duration, item)
+	if err != nil {
+		return task, err
+	}
+	task.EstimatedDuration = duration * gc.hoursPerUnit
+
+	return task, nil
+}
+
+// decodeDocument decodes a JSON document keeping its numbers as json.Number,
+// so ids beyond the precision of a float64 stay exact
+func decodeDocument(body []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid data after the JSON document")
+	}
+
+	return document, nil
+}
+
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// numberAt reads a number or a numeric string from an item
+func numberAt(path *JSONPath, item any) (float64, error) {
+	value, ok := path.Lookup(item)
+	if !ok {
+		return 0, fmt.Errorf("missing value at %s", path)
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		if number, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return number, nil
+		}
+
+		return 0, fmt.Errorf("value %q at %s is not a number", v, path)
+	default:
+		return 0, fmt.Errorf("value %v at %s is not a number", v, path)
+	}
+}
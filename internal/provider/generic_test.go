@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	document := map[string]any{
+		"data": map[string]any{
+			"items": []any{
+				map[string]any{"key": "A-1", "fields": map[string]any{"story points": 3.0}},
+				map[string]any{"key": "A-2", "fields": map[string]any{"story points": 5.0}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   []any
+		wantErr    bool
+	}{
+		{name: "root", expression: "$", expected: []any{document}},
+		{name: "index", expression: "$.data.items[1].key", expected: []any{"A-2"}},
+		{name: "negative index", expression: "data.items[-1].key", expected: []any{"A-2"}},
+		{name: "wildcard", expression: "$.data.items[*].key", expected: []any{"A-1", "A-2"}},
+		{name: "quoted field", expression: "$.data.items[0].fields['story points']", expected: []any{3.0}},
+		{name: "missing field", expression: "$.data.missing.key", expected: nil},
+		{name: "empty field", expression: "$.data..items", wantErr: true},
+		{name: "unclosed bracket", expression: "$.data.items[0", wantErr: true},
+		{name: "invalid index", expression: "$.data.items[first]", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ParseJSONPath(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseJSONPath(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := path.Select(document)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Select() got %d values, want %d", len(got), len(tt.expected))
+			}
+			for i := range got {
+				if tt.name != "root" && got[i] != tt.expected[i] {
+					t.Errorf("Select()[%d] = %v, want %v", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenericClient_FetchTasks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result": {"issues": [
+			{"key": "PRJ-1", "title": "Login page", "meta": {"level": "2"}, "estimate": {"minutes": 90}},
+			{"key": 42, "meta": {"level": 4}, "estimate": {"minutes": 30}}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewGenericClient(GenericConfig{
+		Name:  "tracker",
+		Url:   server.URL,
+		Items: "$.result.issues",
+		Fields: GenericFields{
+			ID:         "$.key",
+			Name:       "$.title",
+			Difficulty: "$.meta.level",
+			Duration:   "$.estimate.minutes",
+		},
+		Units: GenericUnits{Duration: "minutes", DifficultyScale: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("NewGenericClient() error = %v", err)
+	}
+
+	if client.Name() != "tracker" {
+		t.Errorf("Expected name 'tracker', got '%s'", client.Name())
+	}
+
+	tasks, err := client.FetchTasks()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(tasks))
+	}
+
+	if tasks[0].ExternalID != "PRJ-1" || tasks[0].Source != "tracker" || *tasks[0].Name != "Login page" {
+		t.Errorf("Unexpected first task %+v", tasks[0])
+	}
+	if tasks[0].Difficulty != 1 || tasks[0].EstimatedDuration != 1.5 {
+		t.Errorf("Expected difficulty 1 and 1.5 hours, got %f and %f", tasks[0].Difficulty, tasks[0].EstimatedDuration)
+	}
+
+	if tasks[1].ExternalID != "42" || tasks[1].Name != nil {
+		t.Errorf("Expected task 42 without a name, got %+v", tasks[1])
+	}
+}
+
+func TestGenericClient_ParseTasks(t *testing.T) {
+	fields := GenericFields{ID: "id", Difficulty: "difficulty", Duration: "days"}
+
+	tests := []struct {
+		name     string
+		config   GenericConfig
+		body     string
+		duration float64
+		wantErr  bool
+	}{
+		{
+			name:     "top-level list in days",
+			config:   GenericConfig{Name: "feed", Url: "http://feed", Fields: fields, Units: GenericUnits{Duration: "days", HoursPerDay: 6}},
+			body:     `[{"id": 1, "difficulty": 1, "days": 2}]`,
+			duration: 12,
+		},
+		{
+			name:    "missing id",
+			config:  GenericConfig{Name: "feed", Url: "http://feed", Fields: fields},
+			body:    `[{"difficulty": 1, "days": 2}]`,
+			wantErr: true,
+		},
+		{
+			name:    "not a number",
+			config:  GenericConfig{Name: "feed", Url: "http://feed", Fields: fields},
+			body:    `[{"id": 1, "difficulty": "hard", "days": 2}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewGenericClient(tt.config)
+			if err != nil {
+				t.Fatalf("NewGenericClient() error = %v", err)
+			}
+
+			tasks, err := client.ParseTasks([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && tasks[0].EstimatedDuration != tt.duration {
+				t.Errorf("Expected duration %f, got %f", tt.duration, tasks[0].EstimatedDuration)
+			}
+		})
+	}
+}
+
+func TestGenericClient_ParseTasksLargeID(t *testing.T) {
+	client, err := NewGenericClient(GenericConfig{
+		Name:   "feed",
+		Url:    "http://feed",
+		Fields: GenericFields{ID: "id", Difficulty: "difficulty", Duration: "duration"},
+	})
+	if err != nil {
+		t.Fatalf("NewGenericClient() error = %v", err)
+	}
+
+	tasks, err := client.ParseTasks([]byte(`[{"id": 9007199254740993, "difficulty": 1, "duration": 2}]`))
+	if err != nil {
+		t.Fatalf("ParseTasks() error = %v", err)
+	}
+
+	if tasks[0].ExternalID != "9007199254740993" {
+		t.Errorf("Expected external id 9007199254740993, got %s", tasks[0].ExternalID)
+	}
+}
+
+func TestNewGenericClient_HTTPDefaults(t *testing.T) {
+	fields := GenericFields{ID: "id", Difficulty: "difficulty", Duration: "duration"}
+
+	var config GenericConfig
+	err := yaml.Unmarshal([]byte("name: feed\nurl: http://feed\nhttp:\n  retries: 1\n  failure_threshold: 0\n"), &config)
+	if err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	config.Fields = fields
+
+	client, err := NewGenericClient(config)
+	if err != nil {
+		t.Fatalf("NewGenericClient() error = %v", err)
+	}
+
+	want := DefaultHTTPConfig
+	want.Retries = 1
+	want.FailureThreshold = 0
+	if client.client.config != want {
+		t.Errorf("Expected HTTP config %+v, got %+v", want, client.client.config)
+	}
+
+	client, err = NewGenericClient(GenericConfig{Name: "feed", Url: "http://feed", Fields: fields, HTTP: HTTPConfig{Retries: 1}})
+	if err != nil {
+		t.Fatalf("NewGenericClient() error = %v", err)
+	}
+
+	want = DefaultHTTPConfig
+	want.Retries = 1
+	want.FailureThreshold = 0
+	if client.client.config != want {
+		t.Errorf("Expected HTTP config %+v, got %+v", want, client.client.config)
+	}
+}
+
+func TestNewGenericClient_InvalidConfig(t *testing.T) {
+	fields := GenericFields{ID: "id", Difficulty: "difficulty", Duration: "duration"}
+
+	configs := map[string]GenericConfig{
+		"missing name":     {Url: "http://feed", Fields: fields},
+		"missing url":      {Name: "feed", Fields: fields},
+		"missing mapping":  {Name: "feed", Url: "http://feed", Fields: GenericFields{ID: "id"}},
+		"invalid path":     {Name: "feed", Url: "http://feed", Fields: GenericFields{ID: "id[", Difficulty: "d", Duration: "d"}},
+		"unknown duration": {Name: "feed", Url: "http://feed", Fields: fields, Units: GenericUnits{Duration: "fortnights"}},
+	}
+
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewGenericClient(config); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"todo-planning/internal/logger"
+	"todo-planning/internal/model"
+	"todo-planning/internal/utility"
+)
+
+const (
+	defaultGitHubURL         = "https://api.github.com"
+	defaultDifficultyPattern = `^difficulty:\s*(?P<value>\d+(?:\.\d+)?)$`
+	defaultEstimatePattern   = `^estimate:\s*(?P<value>\d+(?:\.\d+)?)\s*(?P<unit>[a-z]*)$`
+	defaultMilestoneFormat   = "{year}-W{week}"
+	gitHubIssuesPerPage      = 100
+	gitHubAPIVersion         = "2022-11-28"
+)
+
+// GitHubConfig describes the repository whose open issues become tasks
+type GitHubConfig struct {
+	Name              string            `yaml:"name"` // also the source of the tasks
+	Url               string            `yaml:"url"`  // API url, https://api.github.com by default
+	Owner             string            `yaml:"owner"`
+	Repo              string            `yaml:"repo"`
+	Labels            []string          `yaml:"labels"`             // only issues with all of these labels
+	DifficultyPattern string            `yaml:"difficulty_pattern"` // label pattern with the difficulty as first or "value" group
+	EstimatePattern   string            `yaml:"estimate_pattern"`   // label pattern with the estimate as first or "value" group and an optional "unit" group
+	HoursPerDay       float64           `yaml:"hours_per_day"`      // converts estimates in days and weeks, 8 by default
+	Assignees         map[string]string `yaml:"assignees"`          // GitHub logins by developer name for pushed assignments, the name when missing
+	MilestoneFormat   string            `yaml:"milestone_format"`   // title of the milestone of a calendar week with {year}, {week} and {date}, "{year}-W{week}" by default
+	HTTP              HTTPConfig        `yaml:"http"`
+	Auth              AuthConfig        `yaml:"auth"` // a bearer token raises the rate limit and gives access to private repositories
+	Incremental       IncrementalConfig `yaml:"incremental"`
+}
+
+// RateLimitError is returned when the rate limit of the GitHub API is exhausted
+// for longer than the HTTP client is willing to wait
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, resets at %s", e.Reset.Format(time.RFC3339))
+}
+
+// GitHubClient fetches the open issues of a GitHub repository
+type GitHubClient struct {
+	name        string
+	url         string
+	client      *HTTPClient
+	pager       *pager
+	incremental IncrementalConfig
+	difficulty  *regexp.Regexp
+	estimate    *regexp.Regexp
+	hoursPerDay float64
+
+	repo            *url.URL // API url of the repository
+	assignees       map[string]string
+	milestoneFormat string
+	milestonesMu    sync.Mutex
+	milestones      map[string]int // milestone numbers by title, loaded with the first push
+}
+
+func NewGitHubClient(config GitHubConfig) (*GitHubClient, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("github provider needs a name")
+	}
+
+	if config.Owner == "" || config.Repo == "" {
+		return nil, fmt.Errorf("github provider %s needs an owner and a repo", config.Name)
+	}
+
+	base, err := url.Parse(withDefault(config.Url, defaultGitHubURL))
+	if err != nil {
+		return nil, fmt.Errorf("github provider %s: invalid url: %w", config.Name, err)
+	}
+
+	repo := base.JoinPath("repos", config.Owner, config.Repo)
+	issues := repo.JoinPath("issues")
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("per_page", strconv.Itoa(gitHubIssuesPerPage))
+	if len(config.Labels) > 0 {
+		query.Set("labels", strings.Join(config.Labels, ","))
+	}
+	issues.RawQuery = query.Encode()
+
+	client := &GitHubClient{
+		name:        config.Name,
+		url:         issues.String(),
+		incremental: config.Incremental,
+		hoursPerDay: config.HoursPerDay,
+
+		repo:            repo,
+		assignees:       config.Assignees,
+		milestoneFormat: withDefault(config.MilestoneFormat, defaultMilestoneFormat),
+	}
+
+	if client.hoursPerDay == 0 {
+		client.hoursPerDay = defaultHoursPerDay
+	}
+
+	if client.difficulty, err = regexp.Compile(withDefault(config.DifficultyPattern, defaultDifficultyPattern)); err != nil {
+		return nil, fmt.Errorf("github provider %s: invalid difficulty pattern: %w", config.Name, err)
+	}
+
+	if client.estimate, err = regexp.Compile(withDefault(config.EstimatePattern, defaultEstimatePattern)); err != nil {
+		return nil, fmt.Errorf("github provider %s: invalid estimate pattern: %w", config.Name, err)
+	}
+
+	for _, pattern := range []*regexp.Regexp{client.difficulty, client.estimate} {
+		if pattern.NumSubexp() == 0 {
+			return nil, fmt.Errorf("github provider %s: pattern %s needs a group for the value", config.Name, pattern)
+		}
+	}
+
+	if !strings.Contains(client.milestoneFormat, "{week}") && !strings.Contains(client.milestoneFormat, "{date}") {
+		return nil, fmt.Errorf("github provider %s: milestone format %q needs {week} or {date}", config.Name, client.milestoneFormat)
+	}
+
+	if client.client, err = newProviderClient(config.HTTP.withDefaults(), config.Auth); err != nil {
+		return nil, fmt.Errorf("github provider %s: %w", config.Name, err)
+	}
+	client.client.header = http.Header{
+		"Accept":               {"application/vnd.github+json"},
+		"X-Github-Api-Version": {gitHubAPIVersion},
+	}
+
+	// the issues API pages through the Link header
+	client.pager, _ = newPager(PaginationConfig{Type: PaginationLink})
+
+	return client, nil
+}
+
+func (ghc *GitHubClient) Name() string {
+	return ghc.name
+}
+
+func (ghc *GitHubClient) FetchTasks() ([]model.Task, error) {
+	return ghc.FetchTasksContext(context.Background())
+}
+
+func (ghc *GitHubClient) FetchTasksContext(ctx context.Context) ([]model.Task, error) {
+	return collect(ctx, ghc)
+}
+
+// StreamTasks hands the tasks to handle page by page
+func (ghc *GitHubClient) StreamTasks(ctx context.Context, handle func(tasks []model.Task) error) error {
+	_, err := ghc.StreamChanges(ctx, Cursor{}, handle)
+	return err
+}
+
+// StreamChanges hands the tasks changed since the cursor to handle page by
+// page. The issues API takes the time of the last sync as "since".
+func (ghc *GitHubClient) StreamChanges(ctx context.Context, cursor Cursor, handle func(tasks []model.Task) error) (Changes, error) {
+	changes, err := fetchChanges(ctx, ghc.client, ghc.pager, ghc.url, ghc.incremental, cursor, func(body []byte) (int, error) {
+		tasks, items, err := ghc.ParseTasks(body)
+		if err != nil {
+			return 0, err
+		}
+
+		return items, handle(tasks)
+	})
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if reset, ok := RateLimitReset(&http.Response{Header: statusErr.Header}); ok {
+			err = &RateLimitError{Reset: reset}
+		}
+	}
+
+	if err != nil {
+		logger.Error(fmt.Errorf("github provider %s: %w", ghc.name, err))
+	}
+
+	return changes, err
+}
+
+// GitHubIssue is an issue as returned by the GitHub REST API
+type GitHubIssue struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Labels      []GitHubLabel   `json:"labels"`
+	PullRequest json.RawMessage `json:"pull_request,omitempty"`
+}
+
+type GitHubLabel struct {
+	Name string `json:"name"`
+}
+
+// ParseTasks maps a page of issues onto tasks, leaving out pull requests. It
+// also returns the number of items on the page.
+func (ghc *GitHubClient) ParseTasks(body []byte) ([]model.Task, int, error) {
+	var issues []GitHubIssue
+	if err := json.Unmarshal(body, &issues); err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	tasks := make([]model.Task, 0, len(issues))
+	for _, issue := range issues {
+		if len(issue.PullRequest) > 0 && string(issue.PullRequest) != "null" {
+			continue
+		}
+
+		task := model.Task{
+			ExternalID: strconv.Itoa(issue.Number),
+			Name:       utility.ToPointer(issue.Title),
+			Source:     ghc.name,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+
+		for _, label := range issue.Labels {
+			name := strings.ToLower(strings.TrimSpace(label.Name))
+
+			if value, _, ok := matchLabel(ghc.difficulty, name); ok && task.Difficulty == 0 {
+				task.Difficulty = value
+			}
+
+			if value, unit, ok := matchLabel(ghc.estimate, name); ok && task.EstimatedDuration == 0 {
+				hours, err := hoursPerUnit(GenericUnits{Duration: unit, HoursPerDay: ghc.hoursPerDay})
+				if err != nil {
+					logger.Info("github provider ", ghc.name, ": ignoring label ", label.Name, " of issue #", issue.Number, ": ", err)
+					continue
+				}
+
+				task.EstimatedDuration = value * hours
+			}
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, len(issues), nil
+}
+
+// matchLabel reads the value and the unit of a label matching a pattern
+func matchLabel(pattern *regexp.Regexp, label string) (float64, string, bool) {
+	groups := pattern.FindStringSubmatch(label)
+	if groups == nil {
+		return 0, "", false
+	}
+
+	valueIndex, unit := 1, ""
+	if i := pattern.SubexpIndex("value"); i > 0 {
+		valueIndex = i
+	}
+	if i := pattern.SubexpIndex("unit"); i > 0 {
+		unit = groups[i]
+	}
+
+	value, err := strconv.ParseFloat(groups[valueIndex], 64)
+	if err != nil || value < 0 {
+		return 0, "", false
+	}
+
+	return value, unit, true
+}
+
+// PushAssignment adds the developer to the assignees of the issue, keeping
+// the ones it already has, and puts it into the milestone of the calendar
+// week, creating the milestone when there is none
+func (ghc *GitHubClient) PushAssignment(ctx context.Context, assignment PlannedAssignment, dryRun bool) (string, error) {
+	number, err := strconv.Atoi(assignment.ExternalID)
+	if err != nil {
+		return "", fmt.Errorf("%q is not an issue number", assignment.ExternalID)
+	}
+
+	login := assignment.Developer
+	if mapped, ok := ghc.assignees[assignment.Developer]; ok {
+		login = mapped
+	}
+	if assignment.WeekStart.IsZero() {
+		return "", fmt.Errorf("assignment of issue #%d has no calendar week", number)
+	}
+	title := ghc.milestoneTitle(assignment.WeekStart)
+	description := fmt.Sprintf("%s to the assignees of issue #%d in milestone %q", login, number, title)
+
+	if dryRun {
+		return "would add " + description, nil
+	}
+
+	milestone, err := ghc.milestone(ctx, title)
+	if err != nil {
+		return "", err
+	}
+
+	issue := ghc.repo.JoinPath("issues", strconv.Itoa(number))
+	if _, err := ghc.send(ctx, http.MethodPost, issue.JoinPath("assignees").String(), map[string]any{"assignees": []string{login}}, nil); err != nil {
+		return "", fmt.Errorf("failed to assign issue #%d: %w", number, err)
+	}
+
+	if _, err := ghc.send(ctx, http.MethodPatch, issue.String(), map[string]any{"milestone": milestone}, nil); err != nil {
+		return "", fmt.Errorf("failed to update issue #%d: %w", number, err)
+	}
+
+	return "added " + description, nil
+}
+
+// milestoneTitle fills the milestone format with the ISO year and week and
+// the date of the Monday of a week
+func (ghc *GitHubClient) milestoneTitle(weekStart time.Time) string {
+	year, week := weekStart.ISOWeek()
+
+	return strings.NewReplacer(
+		"{year}", strconv.Itoa(year),
+		"{week}", fmt.Sprintf("%02d", week),
+		"{date}", weekStart.Format(time.DateOnly),
+	).Replace(ghc.milestoneFormat)
+}
+
+// GitHubMilestone is a milestone as returned by the GitHub REST API
+type GitHubMilestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+// milestone returns the number of the milestone with a title, creating it when
+// the repository has none
+func (ghc *GitHubClient) milestone(ctx context.Context, title string) (int, error) {
+	ghc.milestonesMu.Lock()
+	defer ghc.milestonesMu.Unlock()
+
+	if ghc.milestones == nil {
+		milestones := make(map[string]int)
+
+		query := url.Values{}
+		query.Set("state", "all")
+		query.Set("per_page", strconv.Itoa(gitHubIssuesPerPage))
+		u := ghc.repo.JoinPath("milestones")
+		u.RawQuery = query.Encode()
+
+		for next := u; next != nil; {
+			var page []GitHubMilestone
+			header, err := ghc.send(ctx, http.MethodGet, next.String(), nil, &page)
+			if err != nil {
+				return 0, fmt.Errorf("failed to get milestones: %w", err)
+			}
+
+			for _, milestone := range page {
+				milestones[milestone.Title] = milestone.Number
+			}
+
+			if next, err = nextLink(next, header); err != nil {
+				return 0, fmt.Errorf("failed to get milestones: %w", err)
+			}
+		}
+
+		ghc.milestones = milestones
+	}
+
+	if number, ok := ghc.milestones[title]; ok {
+		return number, nil
+	}
+
+	var created GitHubMilestone
+	if _, err := ghc.send(ctx, http.MethodPost, ghc.repo.JoinPath("milestones").String(), map[string]string{"title": title}, &created); err != nil {
+		return 0, fmt.Errorf("failed to create milestone %q: %w", title, err)
+	}
+	ghc.milestones[title] = created.Number
+
+	return created.Number, nil
+}
+
+// send makes a JSON request to the GitHub API, reads the response into
+// result and returns its header. Only GET requests are retried, creating a
+// milestone twice would fail or duplicate it.
+func (ghc *GitHubClient) send(ctx context.Context, method, rawURL string, body, result any) (http.Header, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	do := ghc.client.Do
+	if method != http.MethodGet {
+		do = ghc.client.DoOnce
+	}
+
+	resp, err := do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if reset, ok := RateLimitReset(resp); ok {
+			return nil, &RateLimitError{Reset: reset}
+		}
+
+		var message struct {
+			Message string `json:"message"`
+		}
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Header: resp.Header}
+		if json.NewDecoder(resp.Body).Decode(&message) == nil && message.Message != "" {
+			return nil, fmt.Errorf("%w: %s", statusErr, message.Message)
+		}
+
+		return nil, statusErr
+	}
+
+	if result == nil {
+		return resp.Header, nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp.Header, nil
+}
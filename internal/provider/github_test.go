@@ -0,0 +1,335 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeGitHub serves the open issues of octo/planner in pages of two like the
+// GitHub REST API, including pull requests and rate limit headers
+type fakeGitHub struct {
+	issues    []map[string]any
+	remaining int       // requests left before the rate limit is exhausted, unlimited when negative
+	reset     time.Time // when an exhausted rate limit is reset
+	requests  []*http.Request
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests = append(f.requests, r)
+
+	if r.URL.Path != "/repos/octo/planner/issues" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if f.remaining == 0 && time.Now().Before(f.reset) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(f.reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+		return
+	}
+	if f.remaining > 0 {
+		f.remaining--
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(f.remaining))
+	}
+
+	const size = 2
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	page = max(page, 1)
+	start := min((page-1)*size, len(f.issues))
+	end := min(start+size, len(f.issues))
+
+	if end < len(f.issues) {
+		next := *r.URL
+		query := next.Query()
+		query.Set("page", strconv.Itoa(page+1))
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.RequestURI()))
+	}
+
+	json.NewEncoder(w).Encode(f.issues[start:end])
+}
+
+func issue(number int, title string, labels ...string) map[string]any {
+	var names []map[string]any
+	for _, label := range labels {
+		names = append(names, map[string]any{"name": label})
+	}
+
+	return map[string]any{"number": number, "title": title, "labels": names}
+}
+
+func TestGitHubClient_FetchTasks(t *testing.T) {
+	t.Setenv("TEST_GITHUB_TOKEN", "gh-token")
+
+	pullRequest := issue(4, "Add sorting")
+	pullRequest["pull_request"] = map[string]any{"url": "https://api.github.com/repos/octo/planner/pulls/4"}
+
+	fake := &fakeGitHub{remaining: -1, issues: []map[string]any{
+		issue(1, "Plan the sprint", "difficulty:3", "estimate:8h"),
+		issue(2, "Fix the login", "bug", "Difficulty: 2", "estimate:1d"),
+		issue(3, "Write docs", "estimate:90m"),
+		pullRequest,
+		issue(5, "Unestimated"),
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	p, err := New("github", "", Options{
+		"url":   server.URL,
+		"owner": "octo",
+		"repo":  "planner",
+		"auth":  map[string]any{"type": "bearer", "token": map[string]any{"env": "TEST_GITHUB_TOKEN"}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tasks, err := p.FetchTasks()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []struct {
+		id         string
+		difficulty float64
+		duration   float64
+	}{
+		{"1", 3, 8},
+		{"2", 2, 8},
+		{"3", 0, 1.5},
+		{"5", 0, 0},
+	}
+	if len(tasks) != len(expected) {
+		t.Fatalf("Expected %d tasks, got %d", len(expected), len(tasks))
+	}
+	for i, task := range tasks {
+		if task.ExternalID != expected[i].id || task.Difficulty != expected[i].difficulty || task.EstimatedDuration != expected[i].duration {
+			t.Errorf("Expected task %s with difficulty %v and duration %v, got %s with %v and %v",
+				expected[i].id, expected[i].difficulty, expected[i].duration, task.ExternalID, task.Difficulty, task.EstimatedDuration)
+		}
+		if task.Source != "github" {
+			t.Errorf("Expected source 'github', got '%s'", task.Source)
+		}
+	}
+
+	if len(fake.requests) != 3 {
+		t.Errorf("Expected 3 pages, got %d requests", len(fake.requests))
+	}
+	for _, r := range fake.requests {
+		if r.Header.Get("Authorization") != "Bearer gh-token" || r.Header.Get("Accept") != "application/vnd.github+json" {
+			t.Errorf("Expected authenticated GitHub API requests, got headers %v", r.Header)
+		}
+		if r.URL.Query().Get("state") != "open" || r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("Expected open issues in pages of 100, got %s", r.URL.RawQuery)
+		}
+	}
+}
+
+func TestGitHubClient_CustomPatterns(t *testing.T) {
+	client, err := NewGitHubClient(GitHubConfig{
+		Name:              "github",
+		Owner:             "octo",
+		Repo:              "planner",
+		DifficultyPattern: `^size/(\d+)$`,
+		EstimatePattern:   `^(?P<value>\d+)(?P<unit>d|w)$`,
+		HoursPerDay:       6,
+	})
+	if err != nil {
+		t.Fatalf("NewGitHubClient() error = %v", err)
+	}
+
+	tasks, items, err := client.ParseTasks([]byte(`[{"number": 7, "title": "Refactor", "labels": [{"name": "size/5"}, {"name": "2d"}]}]`))
+	if err != nil || items != 1 || len(tasks) != 1 {
+		t.Fatalf("Expected 1 task, got %v tasks from %d items: %v", tasks, items, err)
+	}
+	if tasks[0].Difficulty != 5 || tasks[0].EstimatedDuration != 12 {
+		t.Errorf("Expected difficulty 5 and duration 12, got %v and %v", tasks[0].Difficulty, tasks[0].EstimatedDuration)
+	}
+
+	if _, err := NewGitHubClient(GitHubConfig{Name: "github", Owner: "octo", Repo: "planner", DifficultyPattern: "^size$"}); err == nil {
+		t.Error("Expected an error for a pattern without a group")
+	}
+	if _, err := NewGitHubClient(GitHubConfig{Name: "github", Owner: "octo"}); err == nil {
+		t.Error("Expected an error without a repo")
+	}
+	if _, err := NewGitHubClient(GitHubConfig{Name: "github", Owner: "octo", Repo: "planner", MilestoneFormat: "Next sprint"}); err == nil {
+		t.Error("Expected an error for a milestone format without the week")
+	}
+
+	// milestones are named after the ISO week, the default is e.g. 2025-W01
+	client, err = NewGitHubClient(GitHubConfig{Name: "github", Owner: "octo", Repo: "planner"})
+	if err != nil {
+		t.Fatalf("NewGitHubClient() error = %v", err)
+	}
+	if title := client.milestoneTitle(time.Date(2024, time.December, 30, 0, 0, 0, 0, time.UTC)); title != "2025-W01" {
+		t.Errorf("Expected milestone 2025-W01, got %s", title)
+	}
+	client.milestoneFormat = "Week of {date}"
+	if title := client.milestoneTitle(time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)); title != "Week of 2024-06-03" {
+		t.Errorf("Expected milestone 'Week of 2024-06-03', got %s", title)
+	}
+}
+
+func TestGitHubClient_RateLimit(t *testing.T) {
+	fake := &fakeGitHub{
+		remaining: 0,
+		reset:     time.Now().Add(time.Hour),
+		issues:    []map[string]any{issue(1, "Plan the sprint", "difficulty:3", "estimate:8h")},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := NewGitHubClient(GitHubConfig{Name: "github", Url: server.URL, Owner: "octo", Repo: "planner", HTTP: testHTTPConfig})
+	if err != nil {
+		t.Fatalf("NewGitHubClient() error = %v", err)
+	}
+
+	// a reset further away than the longest backoff fails right away
+	var rateLimitErr *RateLimitError
+	if _, err := client.FetchTasks(); !errors.As(err, &rateLimitErr) || rateLimitErr.Reset.Unix() != fake.reset.Unix() {
+		t.Fatalf("Expected a rate limit error resetting at %v, got %v", fake.reset, err)
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("Expected no retry, got %d requests", len(fake.requests))
+	}
+
+	// a reset within the longest backoff is waited for
+	fake.requests = nil
+	fake.reset = time.Now().Add(time.Second).Truncate(time.Second)
+
+	tasks, err := client.FetchTasks()
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("Expected 1 task after the reset, got %v, %v", tasks, err)
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("Expected a retry after the reset, got %d requests", len(fake.requests))
+	}
+}
+
+func TestGitHubClient_PushAssignment(t *testing.T) {
+	var updates []map[string]any
+	var assigned [][]any
+	var created []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/octo/planner/milestones":
+			w.Write([]byte(`[{"number": 1, "title": "Sprint 23"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/octo/planner/milestones":
+			var milestone map[string]string
+			json.NewDecoder(r.Body).Decode(&milestone)
+			created = append(created, milestone["title"])
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"number": %d, "title": %q}`, len(created)+1, milestone["title"])
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/octo/planner/issues/12/assignees":
+			var body map[string][]any
+			json.NewDecoder(r.Body).Decode(&body)
+			assigned = append(assigned, body["assignees"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"number": 12}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/octo/planner/issues/12":
+			var update map[string]any
+			json.NewDecoder(r.Body).Decode(&update)
+			updates = append(updates, update)
+			w.Write([]byte(`{"number": 12}`))
+		default:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message": "Validation Failed"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewGitHubClient(GitHubConfig{
+		Name:            "github",
+		Url:             server.URL,
+		Owner:           "octo",
+		Repo:            "planner",
+		Assignees:       map[string]string{"Dev1": "octocat"},
+		MilestoneFormat: "Sprint {week}",
+		HTTP:            testHTTPConfig,
+	})
+	if err != nil {
+		t.Fatalf("NewGitHubClient() error = %v", err)
+	}
+
+	week23 := time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)
+	week24 := week23.AddDate(0, 0, 7)
+
+	// a dry run describes the change without making it
+	message, err := client.PushAssignment(context.Background(), PlannedAssignment{ExternalID: "12", Developer: "Dev1", Week: 2, WeekStart: week24}, true)
+	if err != nil || message != `would add octocat to the assignees of issue #12 in milestone "Sprint 24"` {
+		t.Errorf("Expected a dry run message, got %q, %v", message, err)
+	}
+	if len(updates) != 0 || len(assigned) != 0 {
+		t.Fatalf("Expected no update in a dry run, got %v and %v", updates, assigned)
+	}
+
+	// the milestone of calendar week 23 exists, the one of week 24 is created once
+	for _, weekStart := range []time.Time{week23, week24, week24} {
+		if _, err := client.PushAssignment(context.Background(), PlannedAssignment{ExternalID: "12", Developer: "Dev1", WeekStart: weekStart}, false); err != nil {
+			t.Fatalf("PushAssignment() error = %v", err)
+		}
+	}
+
+	if len(created) != 1 || created[0] != "Sprint 24" {
+		t.Errorf("Expected milestone 'Sprint 24' to be created once, got %v", created)
+	}
+	// the assignee is added, the issue update only sets the milestone so other assignees are kept
+	wantMilestones := []float64{1, 2, 2}
+	if len(updates) != len(wantMilestones) || len(assigned) != len(wantMilestones) {
+		t.Fatalf("Expected %d assignments and updates, got %v and %v", len(wantMilestones), assigned, updates)
+	}
+	for i, update := range updates {
+		if len(update) != 1 || update["milestone"] != wantMilestones[i] {
+			t.Errorf("Expected issue #12 moved to milestone %v only, got %v", wantMilestones[i], update)
+		}
+		if len(assigned[i]) != 1 || assigned[i][0] != "octocat" {
+			t.Errorf("Expected octocat added to the assignees, got %v", assigned[i])
+		}
+	}
+
+	// creating a milestone isn't repeated when the server fails
+	failures := 0
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`[]`))
+			return
+		}
+
+		failures++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	client.repo, _ = url.Parse(failing.URL + "/repos/octo/planner")
+	client.milestones = nil
+	if _, err := client.PushAssignment(context.Background(), PlannedAssignment{ExternalID: "12", Developer: "Dev1", WeekStart: week23}, false); err == nil {
+		t.Error("Expected an error when the milestone can't be created")
+	}
+	if failures != 1 {
+		t.Errorf("Expected a single attempt to create the milestone, got %d", failures)
+	}
+	client.repo, _ = url.Parse(server.URL + "/repos/octo/planner")
+
+	// unknown issues and ids that aren't issue numbers fail
+	if _, err := client.PushAssignment(context.Background(), PlannedAssignment{ExternalID: "13", Developer: "Dev2", WeekStart: week23}, false); err == nil || !strings.Contains(err.Error(), "Validation Failed") {
+		t.Errorf("Expected the error message of GitHub, got %v", err)
+	}
+	if _, err := client.PushAssignment(context.Background(), PlannedAssignment{ExternalID: "PRJ-1", Developer: "Dev1", WeekStart: week23}, true); err == nil {
+		t.Error("Expected an error for an id that isn't an issue number")
+	}
+	if _, err := client.PushAssignment(context.Background(), PlannedAssignment{ExternalID: "12", Developer: "Dev1", Week: 1}, true); err == nil {
+		t.Error("Expected an error for an assignment without a calendar week")
+	}
+}
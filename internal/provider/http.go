@@ -0,0 +1,394 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"todo-planning/internal/logger"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrCircuitOpen is returned without sending a request while the circuit
+// breaker of a provider is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// HTTPConfig configures the HTTP client shared by the providers. Requests
+// failing with a 5xx or 429 status, a timeout or a connection error are
+// retried with exponential backoff.
+type HTTPConfig struct {
+	Timeout          time.Duration `yaml:"timeout"`           // limit of a single request
+	Retries          int           `yaml:"retries"`           // retries of a failed request
+	BaseDelay        time.Duration `yaml:"base_delay"`        // backoff before the first retry, doubled for every next one
+	MaxDelay         time.Duration `yaml:"max_delay"`         // longest backoff, a longer Retry-After gives up
+	FailureThreshold int           `yaml:"failure_threshold"` // failed requests in a row opening the circuit breaker, 0 disables it
+	Cooldown         time.Duration `yaml:"cooldown"`          // how long an open circuit breaker skips requests
+}
+
+// DefaultHTTPConfig is used for the settings a provider doesn't configure
+var DefaultHTTPConfig = HTTPConfig{
+	Timeout:          15 * time.Second,
+	Retries:          3,
+	BaseDelay:        500 * time.Millisecond,
+	MaxDelay:         30 * time.Second,
+	FailureThreshold: 5,
+	Cooldown:         time.Minute,
+}
+
+// UnmarshalYAML starts from DefaultHTTPConfig, so an http block only
+// overrides the settings it sets
+func (c *HTTPConfig) UnmarshalYAML(node *yaml.Node) error {
+	type plain HTTPConfig
+	config := plain(DefaultHTTPConfig)
+	if err := node.Decode(&config); err != nil {
+		return err
+	}
+
+	*c = HTTPConfig(config)
+	return nil
+}
+
+// withDefaults fills the settings a config left unset with the ones of
+// DefaultHTTPConfig. An empty config is the default one, otherwise only the
+// durations are filled, since 0 retries or a failure threshold of 0 are
+// meaningful.
+func (c HTTPConfig) withDefaults() HTTPConfig {
+	if c == (HTTPConfig{}) {
+		return DefaultHTTPConfig
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = DefaultHTTPConfig.Timeout
+	}
+	if c.BaseDelay == 0 {
+		c.BaseDelay = DefaultHTTPConfig.BaseDelay
+	}
+	if c.MaxDelay == 0 {
+		c.MaxDelay = DefaultHTTPConfig.MaxDelay
+	}
+	if c.Cooldown == 0 {
+		c.Cooldown = DefaultHTTPConfig.Cooldown
+	}
+
+	return c
+}
+
+// HTTPClient sends provider requests, retrying transient failures and
+// skipping a provider that keeps failing
+type HTTPClient struct {
+	client  http.Client
+	config  HTTPConfig
+	breaker *CircuitBreaker
+	auth    Auth
+	header  http.Header // sent with every request that doesn't set it itself
+}
+
+func NewHTTPClient(config HTTPConfig) *HTTPClient {
+	return &HTTPClient{
+		client: http.Client{
+			Timeout: config.Timeout,
+		},
+		config:  config,
+		breaker: NewCircuitBreaker(config.FailureThreshold, config.Cooldown),
+	}
+}
+
+// newProviderClient returns the HTTP client of a provider, authenticating its
+// requests when auth is configured
+func newProviderClient(httpConfig HTTPConfig, authConfig AuthConfig) (*HTTPClient, error) {
+	auth, err := NewAuth(authConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewHTTPClient(httpConfig)
+	client.auth = auth
+
+	return client, nil
+}
+
+// Do sends a request. The response of the last attempt is returned when all
+// retries failed with an error status, so callers still see the status code.
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return c.do(req, c.config.Retries)
+}
+
+// DoOnce sends a request without retrying it, for requests that must not be
+// repeated such as creating a resource
+func (c *HTTPClient) DoOnce(req *http.Request) (*http.Response, error) {
+	return c.do(req, 0)
+}
+
+func (c *HTTPClient) do(req *http.Request, retries int) (*http.Response, error) {
+	if !c.breaker.Allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.auth != nil || len(c.header) > 0 {
+			if attemptReq == req {
+				attemptReq = req.Clone(ctx)
+			}
+
+			for name, values := range c.header {
+				if attemptReq.Header.Get(name) == "" {
+					attemptReq.Header[name] = values
+				}
+			}
+
+			if c.auth != nil {
+				c.auth.Apply(attemptReq)
+			}
+		}
+
+		resp, err := c.client.Do(attemptReq)
+		if err != nil && ctx.Err() != nil {
+			// cancelled by the caller, says nothing about the provider
+			c.breaker.Cancel()
+			return nil, err
+		}
+
+		if !retryable(resp, err) {
+			c.breaker.Success()
+			return resp, err
+		}
+
+		delay, ok := c.backoff(attempt, retries, resp)
+		if !ok {
+			c.breaker.Failure()
+			return resp, err
+		}
+
+		if err != nil {
+			logger.Info("request to ", req.URL.Host, " failed, retrying in ", delay, ": ", err)
+		} else {
+			logger.Info("request to ", req.URL.Host, " failed with status ", resp.StatusCode, ", retrying in ", delay)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			c.breaker.Cancel()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns how long to wait before retrying, false when the request
+// shouldn't be retried anymore
+func (c *HTTPClient) backoff(attempt, retries int, resp *http.Response) (time.Duration, bool) {
+	if attempt >= retries {
+		return 0, false
+	}
+
+	if resp != nil {
+		if delay, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return delay, delay <= c.config.MaxDelay
+		}
+
+		if reset, ok := RateLimitReset(resp); ok {
+			delay := max(time.Until(reset), 0)
+			return delay, delay <= c.config.MaxDelay
+		}
+	}
+
+	delay := c.config.BaseDelay << attempt
+	if delay > c.config.MaxDelay || delay <= 0 {
+		delay = c.config.MaxDelay
+	}
+	if delay <= 0 {
+		return 0, true
+	}
+
+	// half of the delay is random so providers failing together don't retry together
+	return delay/2 + rand.N(delay/2+1), true
+}
+
+// rewind returns the request to send for an attempt, with a fresh body for
+// the retries
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request to %s can't be retried, its body can't be read again", req.URL.Host)
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	retry.Body = body
+
+	return retry, nil
+}
+
+// retryable reports whether a request failed in a way worth retrying
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError, resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusForbidden:
+		// some APIs, like GitHub's, answer 403 when the rate limit is exceeded
+		_, limited := RateLimitReset(resp)
+		return limited || resp.Header.Get("Retry-After") != ""
+	default:
+		return false
+	}
+}
+
+// RateLimitReset returns when an exhausted rate limit is reset, read from the
+// X-RateLimit-Remaining and X-RateLimit-Reset (unix seconds) headers
+func RateLimitReset(resp *http.Response) (time.Time, bool) {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
+
+	seconds, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(seconds, 0), true
+}
+
+// retryAfter parses a Retry-After header in seconds or as a date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// Circuit breaker states
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// CircuitBreaker stops calling a provider after a number of failures in a
+// row. Once the cooldown has passed a single trial request is let through,
+// closing the breaker again when it succeeds.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker returns a breaker opening after threshold failures in a
+// row, a threshold of 0 never opens it
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     circuitClosed,
+	}
+}
+
+// Allow reports whether a request may be sent
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+
+		b.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		// the trial request is still running
+		return false
+	default:
+		return true
+	}
+}
+
+// Success records a request that reached the provider
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = circuitClosed
+	b.failures = 0
+}
+
+// Failure records a request that failed after all its retries
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.threshold <= 0 {
+		return
+	}
+
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		if b.state != circuitOpen {
+			logger.Info("circuit breaker opened after ", b.failures, " failed requests")
+		}
+
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Cancel records a request given up by the caller. A cancelled trial request
+// lets the next request try again.
+func (b *CircuitBreaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == circuitHalfOpen {
+		b.state = circuitOpen
+	}
+}
+
+// State returns closed, open or half-open
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
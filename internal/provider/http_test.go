@@ -0,0 +1,260 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testHTTPConfig retries quickly so the tests don't wait on backoff
+var testHTTPConfig = HTTPConfig{
+	Timeout:          time.Second,
+	Retries:          2,
+	BaseDelay:        time.Millisecond,
+	MaxDelay:         2 * time.Second,
+	FailureThreshold: 2,
+	Cooldown:         time.Minute,
+}
+
+// flakyServer answers with the given statuses in turn, the last one for all
+// remaining requests
+func flakyServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1)) - 1
+		status := statuses[min(call, len(statuses)-1)]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func get(t *testing.T, client *HTTPClient, url string) (*http.Response, error) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	return resp, err
+}
+
+func TestHTTPClient_Retries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{
+			name:       "server error is retried",
+			statuses:   []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK},
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+		},
+		{
+			name:       "retries run out",
+			statuses:   []int{http.StatusServiceUnavailable},
+			wantStatus: http.StatusServiceUnavailable,
+			wantCalls:  3,
+		},
+		{
+			name:       "client error isn't retried",
+			statuses:   []int{http.StatusNotFound, http.StatusOK},
+			wantStatus: http.StatusNotFound,
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := flakyServer(t, tt.statuses...)
+			client := NewHTTPClient(testHTTPConfig)
+
+			resp, err := get(t, client, server.URL)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("Expected %d requests, got %d", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_DoOnce(t *testing.T) {
+	server, calls := flakyServer(t, http.StatusInternalServerError, http.StatusOK)
+	client := NewHTTPClient(testHTTPConfig)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"title": "2024-W23"}`))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	resp, err := client.DoOnce(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError || calls.Load() != 1 {
+		t.Errorf("Expected status 500 after a single request, got %d after %d", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestHTTPClient_RetryAfter(t *testing.T) {
+	server, calls := flakyServer(t, http.StatusTooManyRequests, http.StatusOK)
+	client := NewHTTPClient(testHTTPConfig)
+
+	start := time.Now()
+	resp, err := get(t, client, server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("Expected status 200 after 2 requests, got %d after %d", resp.StatusCode, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected the retry to wait for Retry-After, it came after %v", elapsed)
+	}
+
+	// a Retry-After beyond the longest backoff gives up right away
+	server, calls = flakyServer(t, http.StatusTooManyRequests, http.StatusOK)
+	config := testHTTPConfig
+	config.MaxDelay = 100 * time.Millisecond
+	client = NewHTTPClient(config)
+
+	resp, err = get(t, client, server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Errorf("Expected status 429 after 1 request, got %d after %d", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestHTTPClient_Timeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}))
+	defer server.Close()
+
+	config := testHTTPConfig
+	config.Timeout = 50 * time.Millisecond
+	client := NewHTTPClient(config)
+
+	resp, err := get(t, client, server.URL)
+	if err != nil {
+		t.Fatalf("Expected the timed out request to be retried, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("Expected status 200 after 2 requests, got %d after %d", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestHTTPClient_CircuitBreaker(t *testing.T) {
+	server, calls := flakyServer(t, http.StatusInternalServerError)
+	client := NewHTTPClient(testHTTPConfig)
+
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+
+	// every request fails after its retries, the second one opens the breaker
+	for i := 0; i < 2; i++ {
+		if _, err := get(t, client, server.URL); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if state := client.breaker.State(); state != circuitOpen {
+		t.Fatalf("Expected an open circuit breaker, got %s", state)
+	}
+
+	sent := calls.Load()
+	if _, err := get(t, client, server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected %v, got %v", ErrCircuitOpen, err)
+	}
+	if calls.Load() != sent {
+		t.Errorf("Expected no request while the breaker is open, got %d", calls.Load()-sent)
+	}
+
+	// after the cooldown a trial request goes through and closes the breaker
+	server, _ = flakyServer(t, http.StatusOK)
+	now = now.Add(testHTTPConfig.Cooldown)
+
+	resp, err := get(t, client, server.URL)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the trial request to succeed, got %v", err)
+	}
+	if state := client.breaker.State(); state != circuitClosed {
+		t.Errorf("Expected a closed circuit breaker, got %s", state)
+	}
+}
+
+func TestHTTPClient_Cancel(t *testing.T) {
+	server, calls := flakyServer(t, http.StatusInternalServerError)
+
+	config := testHTTPConfig
+	config.BaseDelay = time.Minute
+	config.MaxDelay = time.Minute
+	client := NewHTTPClient(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected no retry after cancellation, got %d requests", calls.Load())
+	}
+	if state := client.breaker.State(); state != circuitClosed {
+		t.Errorf("Expected a cancelled request not to count as failure, got %s breaker", state)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if delay, ok := retryAfter("3"); !ok || delay != 3*time.Second {
+		t.Errorf("Expected 3s, got %v", delay)
+	}
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if delay, ok := retryAfter(date); !ok || delay < 59*time.Minute {
+		t.Errorf("Expected about an hour, got %v", delay)
+	}
+
+	if _, ok := retryAfter("soon"); ok {
+		t.Error("Expected an invalid Retry-After to be ignored")
+	}
+}
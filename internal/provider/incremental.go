@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"todo-planning/internal/model"
+)
+
+// defaultFullSyncInterval is how often an incremental provider fetches all
+// of its tasks anyway, so tasks removed upstream are noticed
+const defaultFullSyncInterval = 24 * time.Hour
+
+// IncrementalConfig describes how a feed reports what changed since the last sync
+type IncrementalConfig struct {
+	Conditional       bool          `yaml:"conditional"`         // send If-None-Match and If-Modified-Since, nothing is stored on 304 Not Modified
+	UpdatedSinceParam string        `yaml:"updated_since_param"` // query parameter asking only for the tasks changed since the last sync
+	FullSyncInterval  time.Duration `yaml:"full_sync_interval"`  // how often all tasks are fetched anyway, 24h by default
+}
+
+// Enabled reports whether the feed is fetched incrementally
+func (c IncrementalConfig) Enabled() bool {
+	return c.Conditional || c.UpdatedSinceParam != ""
+}
+
+// Cursor is where an incremental provider continues from
+type Cursor struct {
+	ETag         string
+	LastModified string
+	UpdatedSince *time.Time // start of the last successful fetch
+	FullSyncAt   *time.Time // start of the last fetch of all tasks
+}
+
+// Changes is the outcome of an incremental fetch
+type Changes struct {
+	Cursor      *Cursor // where the next fetch continues from, nil when the provider doesn't fetch incrementally
+	NotModified bool    // nothing changed since the cursor and no task was handed over
+	Partial     bool    // only the tasks changed since the cursor were handed over
+}
+
+// IncrementalProvider is implemented by providers that can fetch only what
+// changed since a cursor
+type IncrementalProvider interface {
+	StreamChanges(ctx context.Context, cursor Cursor, handle func(tasks []model.Task) error) (Changes, error)
+}
+
+// StreamChanges hands the tasks changed since the cursor to handle page by
+// page. Providers that can't fetch incrementally hand over all their tasks.
+func StreamChanges(ctx context.Context, p Provider, cursor Cursor, handle func(tasks []model.Task) error) (Changes, error) {
+	if ip, ok := p.(IncrementalProvider); ok {
+		return ip.StreamChanges(ctx, cursor, handle)
+	}
+
+	return Changes{}, Stream(ctx, p, handle)
+}
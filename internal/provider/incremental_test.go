@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"todo-planning/internal/model"
+)
+
+func TestMockTwoClient_StreamChanges(t *testing.T) {
+	const etag = `"v1"`
+	var requests []*http.Request
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 05 Oct 2026 10:00:00 GMT")
+		if r.URL.Query().Has("changed_after") {
+			w.Write([]byte(`[{"id": 2, "zorluk": 3, "sure": 4}]`))
+			return
+		}
+		w.Write([]byte(`[{"id": 1, "zorluk": 1, "sure": 2}, {"id": 2, "zorluk": 3, "sure": 4}]`))
+	}))
+	defer server.Close()
+
+	p, err := New("mock-two", "mock-two", Options{
+		"url":         server.URL,
+		"incremental": map[string]any{"conditional": true, "updated_since_param": "changed_after", "full_sync_interval": "1h"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	client := p.(*MockTwoClient)
+
+	stream := func(cursor Cursor) (Changes, []model.Task) {
+		t.Helper()
+
+		var tasks []model.Task
+		changes, err := client.StreamChanges(context.Background(), cursor, func(page []model.Task) error {
+			tasks = append(tasks, page...)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamChanges() error = %v", err)
+		}
+
+		return changes, tasks
+	}
+
+	// the first sync fetches everything
+	changes, tasks := stream(Cursor{})
+	if len(tasks) != 2 || changes.Partial || changes.NotModified {
+		t.Fatalf("Expected a full fetch of 2 tasks, got %d tasks and %+v", len(tasks), changes)
+	}
+	cursor := changes.Cursor
+	if cursor == nil || cursor.ETag != etag || cursor.LastModified == "" || cursor.UpdatedSince == nil || cursor.FullSyncAt == nil {
+		t.Fatalf("Expected a cursor with validators and sync times, got %+v", cursor)
+	}
+
+	// nothing changed since
+	changes, tasks = stream(*cursor)
+	if !changes.NotModified || len(tasks) != 0 {
+		t.Errorf("Expected not modified, got %d tasks and %+v", len(tasks), changes)
+	}
+	if got := requests[1].Header.Get("If-Modified-Since"); got != cursor.LastModified {
+		t.Errorf("Expected If-Modified-Since %q, got %q", cursor.LastModified, got)
+	}
+	if changes.Cursor == nil || changes.Cursor.ETag != etag {
+		t.Errorf("Expected the cursor to be kept, got %+v", changes.Cursor)
+	}
+
+	// only the changed tasks are fetched while a full sync isn't due
+	changed := *cursor
+	changed.ETag = `"v0"`
+	changes, tasks = stream(changed)
+	if !changes.Partial || len(tasks) != 1 {
+		t.Errorf("Expected a partial fetch of 1 task, got %d tasks and %+v", len(tasks), changes)
+	}
+	if got := requests[2].URL.Query().Get("changed_after"); got != cursor.UpdatedSince.UTC().Format(time.RFC3339) {
+		t.Errorf("Expected changed_after %s, got %q", cursor.UpdatedSince.UTC().Format(time.RFC3339), got)
+	}
+	if changes.Cursor.FullSyncAt != cursor.FullSyncAt {
+		t.Errorf("Expected the last full sync to be kept, got %v", changes.Cursor.FullSyncAt)
+	}
+
+	// everything is fetched again once a full sync is due
+	changed.FullSyncAt = new(time.Time)
+	changes, tasks = stream(changed)
+	if changes.Partial || len(tasks) != 2 {
+		t.Errorf("Expected a full fetch of 2 tasks, got %d tasks and %+v", len(tasks), changes)
+	}
+	if changes.Cursor.FullSyncAt.Before(*cursor.FullSyncAt) {
+		t.Errorf("Expected a new full sync time, got %v", changes.Cursor.FullSyncAt)
+	}
+}
+
+func TestStreamChanges_NotIncremental(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			t.Error("Expected no conditional request")
+		}
+		w.Write([]byte(`[{"id": 1, "value": 2, "estimated_duration": 3}]`))
+	}))
+	defer server.Close()
+
+	changes, err := StreamChanges(context.Background(), NewMockOneClient(server.URL), Cursor{ETag: `"v1"`}, func(tasks []model.Task) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamChanges() error = %v", err)
+	}
+	if changes.Cursor != nil || changes.Partial || changes.NotModified {
+		t.Errorf("Expected a full fetch without cursor, got %+v", changes)
+	}
+}
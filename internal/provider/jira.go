@@ -0,0 +1,283 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-planning/internal/logger"
+	"todo-planning/internal/model"
+	"todo-planning/internal/utility"
+)
+
+const (
+	defaultJiraStoryPointsField = "customfield_10016"
+	defaultJiraDifficulty       = "story_points"
+	defaultJiraDuration         = "timeoriginalestimate / 3600"
+	defaultJiraPageSize         = 50
+	defaultJiraAPIVersion       = 3
+)
+
+// JiraConfig describes the JQL query whose issues become tasks and how their
+// fields map onto difficulty and duration
+type JiraConfig struct {
+	Name       string            `yaml:"name"` // also the source of the tasks
+	Url        string            `yaml:"url"`  // base url of the Jira site, e.g. https://example.atlassian.net
+	JQL        string            `yaml:"jql"`
+	Fields     map[string]string `yaml:"fields"`      // names usable in the formulas by Jira field id, story_points is customfield_10016 by default
+	Difficulty string            `yaml:"difficulty"`  // formula of the difficulty, "story_points" by default
+	Duration   string            `yaml:"duration"`    // formula of the estimate in hours, "timeoriginalestimate / 3600" by default
+	PageSize   int               `yaml:"page_size"`   // issues per request, 50 by default
+	APIVersion int               `yaml:"api_version"` // 3 by default, 2 for Jira Server and Data Center, which lack the search/jql API
+	HTTP       HTTPConfig        `yaml:"http"`
+	Auth       AuthConfig        `yaml:"auth"` // basic auth with an API token on Jira Cloud, bearer with a personal access token on Jira Server
+}
+
+// JiraClient fetches the issues matching a JQL query through the Jira REST API
+type JiraClient struct {
+	name       string
+	url        string
+	fields     map[string]string
+	difficulty *Formula
+	duration   *Formula
+	client     *HTTPClient
+	pager      *pager
+}
+
+func NewJiraClient(config JiraConfig) (*JiraClient, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("jira provider needs a name")
+	}
+
+	if config.Url == "" || config.JQL == "" {
+		return nil, fmt.Errorf("jira provider %s needs a url and a jql query", config.Name)
+	}
+
+	if config.PageSize < 0 {
+		return nil, fmt.Errorf("jira provider %s: page size can't be negative", config.Name)
+	}
+
+	if config.APIVersion == 0 {
+		config.APIVersion = defaultJiraAPIVersion
+	}
+	if config.APIVersion != 2 && config.APIVersion != 3 {
+		return nil, fmt.Errorf("jira provider %s: unsupported api version %d", config.Name, config.APIVersion)
+	}
+
+	base, err := url.Parse(config.Url)
+	if err != nil {
+		return nil, fmt.Errorf("jira provider %s: invalid url: %w", config.Name, err)
+	}
+
+	client := &JiraClient{
+		name:   config.Name,
+		fields: map[string]string{"story_points": defaultJiraStoryPointsField},
+	}
+
+	for name, field := range config.Fields {
+		client.fields[name] = field
+	}
+
+	if client.difficulty, err = ParseFormula(withDefault(config.Difficulty, defaultJiraDifficulty)); err != nil {
+		return nil, fmt.Errorf("jira provider %s, difficulty: %w", config.Name, err)
+	}
+
+	if client.duration, err = ParseFormula(withDefault(config.Duration, defaultJiraDuration)); err != nil {
+		return nil, fmt.Errorf("jira provider %s, duration: %w", config.Name, err)
+	}
+
+	// only the fields the formulas use are requested
+	fields := []string{"summary"}
+	for _, name := range slices.Concat(client.difficulty.Names(), client.duration.Names()) {
+		if field := client.field(name); !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	// version 3 searches through search/jql, the search API of version 2 is
+	// only left on Jira Server and Data Center
+	search := base.JoinPath("rest", "api", "3", "search", "jql")
+	if config.APIVersion == 2 {
+		search = base.JoinPath("rest", "api", "2", "search")
+	}
+	query := url.Values{}
+	query.Set("jql", config.JQL)
+	query.Set("fields", strings.Join(fields, ","))
+	search.RawQuery = query.Encode()
+	client.url = search.String()
+
+	if client.client, err = newProviderClient(config.HTTP.withDefaults(), config.Auth); err != nil {
+		return nil, fmt.Errorf("jira provider %s: %w", config.Name, err)
+	}
+
+	if config.PageSize == 0 {
+		config.PageSize = defaultJiraPageSize
+	}
+
+	// search/jql hands out a token for the next page until isLast. The older
+	// search API pages through startAt instead, and since Jira caps
+	// maxResults on the server only its total ends the search.
+	pagination := PaginationConfig{
+		Type:        PaginationCursor,
+		CursorParam: "nextPageToken",
+		CursorPath:  "$.nextPageToken",
+		LastPath:    "$.isLast",
+		SizeParam:   "maxResults",
+		Size:        config.PageSize,
+	}
+	if config.APIVersion == 2 {
+		pagination = PaginationConfig{
+			Type:        PaginationOffset,
+			OffsetParam: "startAt",
+			SizeParam:   "maxResults",
+			Size:        config.PageSize,
+			TotalPath:   "$.total",
+		}
+	}
+	client.pager, _ = newPager(pagination)
+
+	return client, nil
+}
+
+func (jc *JiraClient) Name() string {
+	return jc.name
+}
+
+func (jc *JiraClient) FetchTasks() ([]model.Task, error) {
+	return jc.FetchTasksContext(context.Background())
+}
+
+func (jc *JiraClient) FetchTasksContext(ctx context.Context) ([]model.Task, error) {
+	return collect(ctx, jc)
+}
+
+// StreamTasks hands the tasks to handle page by page
+func (jc *JiraClient) StreamTasks(ctx context.Context, handle func(tasks []model.Task) error) error {
+	_, err := fetchChanges(ctx, jc.client, jc.pager, jc.url, IncrementalConfig{}, Cursor{}, func(body []byte) (int, error) {
+		tasks, items, err := jc.ParseTasks(body)
+		if err != nil {
+			return 0, err
+		}
+
+		return items, handle(tasks)
+	})
+
+	if err != nil {
+		logger.Error(fmt.Errorf("jira provider %s: %w", jc.name, err))
+	}
+
+	return err
+}
+
+// JiraSearchResult is a page of the search API, paged by token in version 3
+// and by startAt in version 2
+type JiraSearchResult struct {
+	NextPageToken string      `json:"nextPageToken"`
+	IsLast        bool        `json:"isLast"`
+	StartAt       int         `json:"startAt"`
+	MaxResults    int         `json:"maxResults"`
+	Total         int         `json:"total"`
+	Issues        []JiraIssue `json:"issues"`
+}
+
+// JiraIssue is an issue with the requested fields by field id
+type JiraIssue struct {
+	Key    string         `json:"key"`
+	Fields map[string]any `json:"fields"`
+}
+
+// ParseTasks maps a page of search results onto tasks. It also returns the
+// number of issues on the page.
+func (jc *JiraClient) ParseTasks(body []byte) ([]model.Task, int, error) {
+	var result JiraSearchResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	tasks := make([]model.Task, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		task, err := jc.toTask(issue)
+		if err != nil {
+			return nil, 0, fmt.Errorf("issue %s: %w", issue.Key, err)
+		}
+
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		tasks = append(tasks, task)
+	}
+
+	return tasks, len(result.Issues), nil
+}
+
+func (jc *JiraClient) toTask(issue JiraIssue) (model.Task, error) {
+	task := model.Task{ExternalID: issue.Key, Source: jc.name}
+
+	if issue.Key == "" {
+		return task, fmt.Errorf("missing key")
+	}
+
+	if summary, ok := issue.Fields["summary"].(string); ok {
+		task.Name = utility.ToPointer(summary)
+	}
+
+	values := func(name string) (float64, error) {
+		return jiraNumber(issue.Fields[jc.field(name)], name)
+	}
+
+	var err error
+	if task.Difficulty, err = jc.difficulty.Eval(values); err != nil {
+		return task, fmt.Errorf("difficulty: %w", err)
+	}
+
+	if task.EstimatedDuration, err = jc.duration.Eval(values); err != nil {
+		return task, fmt.Errorf("duration: %w", err)
+	}
+
+	if task.Difficulty < 0 || task.EstimatedDuration < 0 {
+		return task, fmt.Errorf("difficulty %v and duration %v can't be negative", task.Difficulty, task.EstimatedDuration)
+	}
+
+	return task, nil
+}
+
+// field returns the Jira field id of a name used in a formula
+func (jc *JiraClient) field(name string) string {
+	if field, ok := jc.fields[name]; ok {
+		return field
+	}
+
+	return name
+}
+
+// jiraNumber reads a numeric field. Unset fields count as 0, options of
+// select fields by their value.
+func jiraNumber(value any, name string) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return 0, nil
+		}
+
+		if number, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return number, nil
+		}
+
+		return 0, fmt.Errorf("%s %q is not a number", name, v)
+	case map[string]any:
+		if option, ok := v["value"]; ok {
+			return jiraNumber(option, name)
+		}
+	}
+
+	return 0, fmt.Errorf("%s %v is not a number", name, value)
+}
@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseFormula(t *testing.T) {
+	values := map[string]float64{"story_points": 3, "timeoriginalestimate": 7200, "zero": 0}
+	lookup := func(name string) (float64, error) {
+		return values[name], nil
+	}
+
+	tests := []struct {
+		expression string
+		expected   float64
+		wantErr    bool
+	}{
+		{expression: "story_points", expected: 3},
+		{expression: "2 + 3 * 4", expected: 14},
+		{expression: "(2 + 3) * 4", expected: 20},
+		{expression: "-story_points + 10 / 4", expected: -0.5},
+		{expression: "timeoriginalestimate / 3600 + story_points * 0.5", expected: 3.5},
+		{expression: "ceil(story_points / 2)", expected: 2},
+		{expression: "max(story_points, 5, 1)", expected: 5},
+		{expression: "min(round(2.6), floor(3.9))", expected: 3},
+		{expression: "story_points / zero", wantErr: true},
+		{expression: "", wantErr: true},
+		{expression: "2 +", wantErr: true},
+		{expression: "(2 + 3", wantErr: true},
+		{expression: "sqrt(4)", wantErr: true},
+		{expression: "ceil(1, 2)", wantErr: true},
+		{expression: "2 $ 3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			formula, err := ParseFormula(tt.expression)
+			if err == nil {
+				var got float64
+				got, err = formula.Eval(lookup)
+				if err == nil && got != tt.expected {
+					t.Errorf("Eval() = %v, want %v", got, tt.expected)
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFormula(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeJira serves the search APIs of a Jira site with the given issues. Like
+// Jira it returns at most limit issues per page whatever maxResults asks for.
+// search/jql pages by token, the search API of version 2 by startAt.
+func fakeJira(issues []map[string]any, limit int, requests *[]*http.Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+
+		if r.URL.Path != "/rest/api/3/search/jql" && r.URL.Path != "/rest/api/2/search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if user, password, ok := r.BasicAuth(); !ok || user != "planner@example.com" || password != "jira-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		query := r.URL.Query()
+		startAt, _ := strconv.Atoi(query.Get("startAt"))
+		if r.URL.Path == "/rest/api/3/search/jql" {
+			startAt, _ = strconv.Atoi(query.Get("nextPageToken"))
+		}
+		maxResults, _ := strconv.Atoi(query.Get("maxResults"))
+		maxResults = min(maxResults, limit)
+		start := min(startAt, len(issues))
+		end := min(start+maxResults, len(issues))
+
+		if r.URL.Path == "/rest/api/2/search" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"startAt":    startAt,
+				"maxResults": maxResults,
+				"total":      len(issues),
+				"issues":     issues[start:end],
+			})
+			return
+		}
+
+		response := map[string]any{"isLast": end == len(issues), "issues": issues[start:end]}
+		if end < len(issues) {
+			response["nextPageToken"] = strconv.Itoa(end)
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestJiraClient_FetchTasks(t *testing.T) {
+	t.Setenv("TEST_JIRA_TOKEN", "jira-token")
+
+	var requests []*http.Request
+	server := fakeJira([]map[string]any{
+		{"key": "PLAN-1", "fields": map[string]any{"summary": "Sprint planning", "customfield_10020": 3.0, "customfield_10030": map[string]any{"value": "2"}}},
+		{"key": "PLAN-2", "fields": map[string]any{"summary": "Login page", "customfield_10020": "5", "customfield_10030": nil}},
+		{"key": "PLAN-3", "fields": map[string]any{"summary": "Unestimated"}},
+	}, 50, &requests)
+	defer server.Close()
+
+	p, err := New("jira", "", Options{
+		"url":        server.URL,
+		"jql":        `project = PLAN AND statusCategory != Done`,
+		"fields":     map[string]any{"story_points": "customfield_10020", "risk": "customfield_10030"},
+		"difficulty": "story_points + risk",
+		"duration":   "story_points * 4",
+		"page_size":  2,
+		"auth":       map[string]any{"type": "basic", "username": "planner@example.com", "password": map[string]any{"env": "TEST_JIRA_TOKEN"}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tasks, err := p.FetchTasks()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []struct {
+		key        string
+		difficulty float64
+		duration   float64
+	}{
+		{"PLAN-1", 5, 12},
+		{"PLAN-2", 5, 20},
+		{"PLAN-3", 0, 0},
+	}
+	if len(tasks) != len(expected) {
+		t.Fatalf("Expected %d tasks, got %d", len(expected), len(tasks))
+	}
+	for i, task := range tasks {
+		if task.ExternalID != expected[i].key || task.Difficulty != expected[i].difficulty || task.EstimatedDuration != expected[i].duration {
+			t.Errorf("Expected task %s with difficulty %v and duration %v, got %s with %v and %v",
+				expected[i].key, expected[i].difficulty, expected[i].duration, task.ExternalID, task.Difficulty, task.EstimatedDuration)
+		}
+		if task.Source != "jira" {
+			t.Errorf("Expected source 'jira', got '%s'", task.Source)
+		}
+	}
+	if tasks[0].Name == nil || *tasks[0].Name != "Sprint planning" {
+		t.Errorf("Expected the summary as name, got %v", tasks[0].Name)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 pages, got %d requests", len(requests))
+	}
+	if path := requests[0].URL.Path; path != "/rest/api/3/search/jql" {
+		t.Errorf("Expected the search/jql API, got %s", path)
+	}
+	query := requests[1].URL.Query()
+	if query.Get("jql") != `project = PLAN AND statusCategory != Done` || query.Get("nextPageToken") != "2" {
+		t.Errorf("Expected the second page of the jql query, got %s", requests[1].URL.RawQuery)
+	}
+	if fields := strings.Split(query.Get("fields"), ","); len(fields) != 3 || fields[0] != "summary" {
+		t.Errorf("Expected only the summary and the fields of the formulas, got %v", fields)
+	}
+}
+
+func TestJiraClient_CappedPageSize(t *testing.T) {
+	t.Setenv("TEST_JIRA_TOKEN", "jira-token")
+
+	issues := make([]map[string]any, 5)
+	for i := range issues {
+		issues[i] = map[string]any{"key": fmt.Sprintf("PLAN-%d", i+1), "fields": map[string]any{"summary": "Issue"}}
+	}
+
+	tests := []struct {
+		apiVersion int
+		param      string
+	}{
+		{apiVersion: 3, param: "nextPageToken"},
+		{apiVersion: 2, param: "startAt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("version %d", tt.apiVersion), func(t *testing.T) {
+			var requests []*http.Request
+			server := fakeJira(issues, 2, &requests)
+			defer server.Close()
+
+			p, err := New("jira", "", Options{
+				"url":         server.URL,
+				"jql":         "project = PLAN",
+				"page_size":   200,
+				"api_version": tt.apiVersion,
+				"auth":        map[string]any{"type": "basic", "username": "planner@example.com", "password": map[string]any{"env": "TEST_JIRA_TOKEN"}},
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			tasks, err := p.FetchTasks()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(tasks) != len(issues) {
+				t.Fatalf("Expected all %d issues although the server caps maxResults, got %d", len(issues), len(tasks))
+			}
+			if len(requests) != 3 {
+				t.Errorf("Expected 3 pages, got %d requests", len(requests))
+			}
+			if start := requests[2].URL.Query().Get(tt.param); start != "4" {
+				t.Errorf("Expected the last page to start at 4, got %s", start)
+			}
+		})
+	}
+}
+
+func TestJiraClient_DefaultFormulas(t *testing.T) {
+	client, err := NewJiraClient(JiraConfig{Name: "jira", Url: "https://example.atlassian.net", JQL: "project = PLAN"})
+	if err != nil {
+		t.Fatalf("NewJiraClient() error = %v", err)
+	}
+
+	tasks, items, err := client.ParseTasks([]byte(`{"issues": [{"key": "PLAN-1", "fields": {"customfield_10016": 8, "timeoriginalestimate": 5400}}]}`))
+	if err != nil || items != 1 || len(tasks) != 1 {
+		t.Fatalf("Expected 1 task, got %v tasks from %d items: %v", tasks, items, err)
+	}
+	if tasks[0].Difficulty != 8 || tasks[0].EstimatedDuration != 1.5 {
+		t.Errorf("Expected difficulty 8 and duration 1.5, got %v and %v", tasks[0].Difficulty, tasks[0].EstimatedDuration)
+	}
+
+	// a field that isn't a number fails the page
+	if _, _, err := client.ParseTasks([]byte(`{"issues": [{"key": "PLAN-2", "fields": {"customfield_10016": "large"}}]}`)); err == nil {
+		t.Error("Expected an error for story points that aren't a number")
+	}
+}
+
+func TestNewJiraClient_InvalidConfig(t *testing.T) {
+	configs := []JiraConfig{
+		{Url: "https://example.atlassian.net", JQL: "project = PLAN"},
+		{Name: "jira", JQL: "project = PLAN"},
+		{Name: "jira", Url: "https://example.atlassian.net"},
+		{Name: "jira", Url: "https://example.atlassian.net", JQL: "project = PLAN", Difficulty: "story_points *"},
+		{Name: "jira", Url: "https://example.atlassian.net", JQL: "project = PLAN", Duration: "hours(2)"},
+		{Name: "jira", Url: "https://example.atlassian.net", JQL: "project = PLAN", PageSize: -1},
+		{Name: "jira", Url: "https://example.atlassian.net", JQL: "project = PLAN", APIVersion: 1},
+	}
+
+	for _, config := range configs {
+		if _, err := NewJiraClient(config); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a compiled path expression selecting a value in a decoded JSON
+// document. It supports the subset of JSONPath needed to map provider
+// responses: the root "$", fields ".name" or "['name']", array indexes
+// "[0]" and the wildcard "[*]" that collects every element of an array.
+type JSONPath struct {
+	expression string
+	segments   []pathSegment
+}
+
+type pathSegment struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// ParseJSONPath compiles a path expression such as "$.data.items[*]" or
+// "fields['story points']". The leading "$" is optional.
+func ParseJSONPath(expression string) (*JSONPath, error) {
+	path := &JSONPath{expression: expression}
+
+	rest := strings.TrimSpace(expression)
+	rest = strings.TrimPrefix(rest, "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+
+			field := rest[:end]
+			if field == "" {
+				return nil, fmt.Errorf("invalid path %q: empty field name", expression)
+			}
+
+			path.segments = append(path.segments, pathSegment{field: field})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", expression)
+			}
+
+			segment, err := parseBracket(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", expression, err)
+			}
+
+			path.segments = append(path.segments, segment)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", expression, rest[0])
+		}
+	}
+
+	return path, nil
+}
+
+func parseBracket(content string) (pathSegment, error) {
+	content = strings.TrimSpace(content)
+
+	switch {
+	case content == "*":
+		return pathSegment{wildcard: true}, nil
+	case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+		return pathSegment{field: content[1 : len(content)-1]}, nil
+	default:
+		index, err := strconv.Atoi(content)
+		if err != nil {
+			return pathSegment{}, fmt.Errorf("invalid index %q", content)
+		}
+
+		return pathSegment{index: index, isIndex: true}, nil
+	}
+}
+
+func (p *JSONPath) String() string {
+	return p.expression
+}
+
+// Select returns the values the path points to. Without a wildcard there is
+// at most one value, missing fields select nothing.
+func (p *JSONPath) Select(document any) []any {
+	values := []any{document}
+
+	for _, segment := range p.segments {
+		var next []any
+		for _, value := range values {
+			next = append(next, segment.apply(value)...)
+		}
+
+		values = next
+	}
+
+	return values
+}
+
+// Lookup returns the single value the path points to and whether it exists
+func (p *JSONPath) Lookup(document any) (any, bool) {
+	values := p.Select(document)
+	if len(values) == 0 || values[0] == nil {
+		return nil, false
+	}
+
+	return values[0], true
+}
+
+func (s pathSegment) apply(value any) []any {
+	switch {
+	case s.wildcard:
+		switch v := value.(type) {
+		case []any:
+			return v
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			values := make([]any, 0, len(v))
+			for _, key := range keys {
+				values = append(values, v[key])
+			}
+
+			return values
+		}
+	case s.isIndex:
+		if items, ok := value.([]any); ok {
+			index := s.index
+			if index < 0 {
+				index += len(items)
+			}
+
+			if index >= 0 && index < len(items) {
+				return []any{items[index]}
+			}
+		}
+	default:
+		if object, ok := value.(map[string]any); ok {
+			if field, ok := object[s.field]; ok {
+				return []any{field}
+			}
+		}
+	}
+
+	return nil
+}
@@ -1,10 +1,9 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"strconv"
 	"time"
 
@@ -15,50 +14,81 @@ import (
 
 func NewMockOneClient(url string) *MockOneClient {
 	return &MockOneClient{
-		url: url,
-		Client: http.Client{
-			Timeout: 15 * time.Second,
-		},
+		name:   "mock-one",
+		url:    url,
+		client: NewHTTPClient(DefaultHTTPConfig),
+		pager:  singlePage(),
 	}
 }
 
 type MockOneClient struct {
-	url string
-	http.Client
+	name        string
+	url         string
+	client      *HTTPClient
+	pager       *pager
+	incremental IncrementalConfig
+}
+
+func (moc *MockOneClient) Name() string {
+	return moc.name
 }
 
 func (moc *MockOneClient) FetchTasks() ([]model.Task, error) {
-	var tasks []*MockOneTask
+	return moc.FetchTasksContext(context.Background())
+}
 
-	resp, err := moc.Client.Get(moc.url)
-	if err != nil {
-		logger.Error(err)
+func (moc *MockOneClient) FetchTasksContext(ctx context.Context) ([]model.Task, error) {
+	return collect(ctx, moc)
+}
 
-		return nil, err
-	}
+// StreamTasks hands the tasks to handle page by page
+func (moc *MockOneClient) StreamTasks(ctx context.Context, handle func(tasks []model.Task) error) error {
+	_, err := moc.StreamChanges(ctx, Cursor{}, handle)
+	return err
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
-	}
+// StreamChanges hands the tasks changed since the cursor to handle page by page
+func (moc *MockOneClient) StreamChanges(ctx context.Context, cursor Cursor, handle func(tasks []model.Task) error) (Changes, error) {
+	changes, err := fetchChanges(ctx, moc.client, moc.pager, moc.url, moc.incremental, cursor, func(body []byte) (int, error) {
+		var tasks []*MockOneTask
+		if err := json.Unmarshal(body, &tasks); err != nil {
+			return 0, err
+		}
+
+		var result []model.Task
 
-	var b []byte
+		for i := range tasks {
+			task := tasks[i].ToTask()
+			task.Source = moc.name
+			result = append(result, task)
+		}
 
-	b, err = io.ReadAll(resp.Body)
+		return len(tasks), handle(result)
+	})
 	if err != nil {
 		logger.Error(err)
-
-		return nil, err
 	}
 
-	if err := json.Unmarshal(b, &tasks); err != nil {
-		logger.Error(err)
+	return changes, err
+}
+
+// ParseWebhook reads pushed tasks in the format of the feed
+func (moc *MockOneClient) ParseWebhook(event string, body []byte) ([]model.Task, error) {
+	items, err := webhookItems(body)
+	if err != nil {
 		return nil, err
 	}
 
-	var result []model.Task
+	result := make([]model.Task, 0, len(items))
+	for i, item := range items {
+		var task MockOneTask
+		if err := json.Unmarshal(item, &task); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
 
-	for i := range tasks {
-		result = append(result, tasks[i].ToTask())
+		parsed := task.ToTask()
+		parsed.Source = moc.name
+		result = append(result, parsed)
 	}
 
 	return result, nil
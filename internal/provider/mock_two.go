@@ -1,10 +1,9 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"strconv"
 	"time"
 
@@ -15,50 +14,82 @@ import (
 
 // MockTwoClient implements the Client interface for mock-two API
 type MockTwoClient struct {
-	url string
-	http.Client
+	name        string
+	url         string
+	client      *HTTPClient
+	pager       *pager
+	incremental IncrementalConfig
 }
 
 func NewMockTwoClient(url string) *MockTwoClient {
 	return &MockTwoClient{
-		url: url,
-		Client: http.Client{
-			Timeout: time.Second * 15,
-		},
+		name:   "mock-two",
+		url:    url,
+		client: NewHTTPClient(DefaultHTTPConfig),
+		pager:  singlePage(),
 	}
 }
 
+func (mtc *MockTwoClient) Name() string {
+	return mtc.name
+}
+
 func (mtc *MockTwoClient) FetchTasks() ([]model.Task, error) {
-	var tasks []*MockTwoTask
+	return mtc.FetchTasksContext(context.Background())
+}
 
-	resp, err := mtc.Client.Get(mtc.url)
-	if err != nil {
-		logger.Error(err)
+func (mtc *MockTwoClient) FetchTasksContext(ctx context.Context) ([]model.Task, error) {
+	return collect(ctx, mtc)
+}
 
-		return nil, err
-	}
+// StreamTasks hands the tasks to handle page by page
+func (mtc *MockTwoClient) StreamTasks(ctx context.Context, handle func(tasks []model.Task) error) error {
+	_, err := mtc.StreamChanges(ctx, Cursor{}, handle)
+	return err
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
-	}
-	var b []byte
+// StreamChanges hands the tasks changed since the cursor to handle page by page
+func (mtc *MockTwoClient) StreamChanges(ctx context.Context, cursor Cursor, handle func(tasks []model.Task) error) (Changes, error) {
+	changes, err := fetchChanges(ctx, mtc.client, mtc.pager, mtc.url, mtc.incremental, cursor, func(body []byte) (int, error) {
+		var tasks []*MockTwoTask
+		if err := json.Unmarshal(body, &tasks); err != nil {
+			return 0, err
+		}
+
+		var result []model.Task
+
+		for i := range tasks {
+			task := tasks[i].ToTask()
+			task.Source = mtc.name
+			result = append(result, task)
+		}
 
-	b, err = io.ReadAll(resp.Body)
+		return len(tasks), handle(result)
+	})
 	if err != nil {
 		logger.Error(err)
-
-		return nil, err
 	}
 
-	if err := json.Unmarshal(b, &tasks); err != nil {
-		logger.Error(err)
+	return changes, err
+}
+
+// ParseWebhook reads pushed tasks in the format of the feed
+func (mtc *MockTwoClient) ParseWebhook(event string, body []byte) ([]model.Task, error) {
+	items, err := webhookItems(body)
+	if err != nil {
 		return nil, err
 	}
 
-	var result []model.Task
+	result := make([]model.Task, 0, len(items))
+	for i, item := range items {
+		var task MockTwoTask
+		if err := json.Unmarshal(item, &task); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
 
-	for i := range tasks {
-		result = append(result, tasks[i].ToTask())
+		parsed := task.ToTask()
+		parsed.Source = mtc.name
+		result = append(result, parsed)
 	}
 
 	return result, nil
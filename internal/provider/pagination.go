@@ -0,0 +1,434 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-planning/internal/model"
+)
+
+// Pagination types
+const (
+	PaginationNone   = ""
+	PaginationPage   = "page"   // page number and page size parameters
+	PaginationOffset = "offset" // offset and limit parameters
+	PaginationCursor = "cursor" // the next cursor is read from the response body
+	PaginationLink   = "link"   // the next page is the rel="next" entry of the Link header
+)
+
+// defaultMaxPages stops a feed that never runs out of pages
+const defaultMaxPages = 1000
+
+// PaginationConfig describes how the pages of a feed are requested
+type PaginationConfig struct {
+	Type        string `yaml:"type"`         // page, offset, cursor or link, a single request when empty
+	PageParam   string `yaml:"page_param"`   // page number parameter, "page" by default
+	StartPage   int    `yaml:"start_page"`   // number of the first page, 1 by default
+	OffsetParam string `yaml:"offset_param"` // offset parameter, "offset" by default
+	SizeParam   string `yaml:"size_param"`   // page size parameter, "per_page" or "limit" by default
+	Size        int    `yaml:"size"`         // page size, the size parameter is left out when 0
+	CursorParam string `yaml:"cursor_param"` // cursor parameter, "cursor" by default
+	CursorPath  string `yaml:"cursor_path"`  // path to the next cursor in the response, e.g. $.meta.next
+	LastPath    string `yaml:"last_path"`    // path to a flag that is true on the last page, e.g. $.isLast, the missing cursor ends the feed otherwise
+	TotalPath   string `yaml:"total_path"`   // path to the total number of items in the response, e.g. $.total
+	MaxPages    int    `yaml:"max_pages"`    // most pages fetched, 1000 by default
+}
+
+// StreamProvider is implemented by providers that hand over their tasks page
+// by page, so a large backlog doesn't have to be held in memory at once
+type StreamProvider interface {
+	StreamTasks(ctx context.Context, handle func(tasks []model.Task) error) error
+}
+
+// Stream hands the tasks of a provider to handle page by page. Providers
+// that can't stream hand over all their tasks at once.
+func Stream(ctx context.Context, p Provider, handle func(tasks []model.Task) error) error {
+	if sp, ok := p.(StreamProvider); ok {
+		return sp.StreamTasks(ctx, handle)
+	}
+
+	tasks, err := Fetch(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	return handle(tasks)
+}
+
+// collect fetches all pages of a stream provider
+func collect(ctx context.Context, p StreamProvider) ([]model.Task, error) {
+	var result []model.Task
+	err := p.StreamTasks(ctx, func(tasks []model.Task) error {
+		result = append(result, tasks...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// pager walks the pages of a feed
+type pager struct {
+	config PaginationConfig
+	cursor *JSONPath
+	last   *JSONPath
+	total  *JSONPath
+}
+
+func newPager(config PaginationConfig) (*pager, error) {
+	p := &pager{config: config}
+
+	switch config.Type {
+	case PaginationNone, PaginationLink:
+	case PaginationPage:
+		p.config.PageParam = withDefault(config.PageParam, "page")
+		p.config.SizeParam = withDefault(config.SizeParam, "per_page")
+		if config.StartPage == 0 {
+			p.config.StartPage = 1
+		}
+	case PaginationOffset:
+		p.config.OffsetParam = withDefault(config.OffsetParam, "offset")
+		p.config.SizeParam = withDefault(config.SizeParam, "limit")
+	case PaginationCursor:
+		if config.CursorPath == "" {
+			return nil, fmt.Errorf("cursor pagination needs a cursor_path")
+		}
+
+		var err error
+		if p.cursor, err = ParseJSONPath(config.CursorPath); err != nil {
+			return nil, fmt.Errorf("cursor pagination: %w", err)
+		}
+		p.config.CursorParam = withDefault(config.CursorParam, "cursor")
+		p.config.SizeParam = withDefault(config.SizeParam, "limit")
+
+		if config.LastPath != "" {
+			if p.last, err = ParseJSONPath(config.LastPath); err != nil {
+				return nil, fmt.Errorf("last_path: %w", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown pagination type %q", config.Type)
+	}
+
+	if config.LastPath != "" && config.Type != PaginationCursor {
+		return nil, fmt.Errorf("last_path needs cursor pagination")
+	}
+
+	if config.TotalPath != "" {
+		// servers may return fewer items than asked for, only the offset tells how far the feed has been read
+		if config.Type != PaginationOffset {
+			return nil, fmt.Errorf("total_path needs offset pagination")
+		}
+
+		var err error
+		if p.total, err = ParseJSONPath(config.TotalPath); err != nil {
+			return nil, fmt.Errorf("total_path: %w", err)
+		}
+	}
+
+	if config.Size < 0 {
+		return nil, fmt.Errorf("page size can't be negative")
+	}
+
+	if config.MaxPages <= 0 {
+		p.config.MaxPages = defaultMaxPages
+	}
+
+	return p, nil
+}
+
+// singlePage returns the pager of a feed without pagination
+func singlePage() *pager {
+	return &pager{config: PaginationConfig{MaxPages: 1}}
+}
+
+// first returns the url of the first page
+func (p *pager) first(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+
+	query := u.Query()
+	switch p.config.Type {
+	case PaginationPage:
+		query.Set(p.config.PageParam, strconv.Itoa(p.config.StartPage))
+	case PaginationOffset:
+		query.Set(p.config.OffsetParam, "0")
+	}
+
+	if p.config.Type != PaginationNone && p.config.Type != PaginationLink && p.config.Size > 0 {
+		query.Set(p.config.SizeParam, strconv.Itoa(p.config.Size))
+	}
+	u.RawQuery = query.Encode()
+
+	return u, nil
+}
+
+// next returns the url of the page after the current one, nil after the last page
+func (p *pager) next(current *url.URL, resp *http.Response, body []byte, items int) (*url.URL, error) {
+	switch p.config.Type {
+	case PaginationPage, PaginationOffset:
+		if items == 0 {
+			return nil, nil
+		}
+
+		next := *current
+		query := next.Query()
+		offset, _ := strconv.Atoi(query.Get(p.config.OffsetParam))
+
+		// with a total a short page only means the server capped the page size
+		total, ok, err := p.totalItems(body)
+		if err != nil {
+			return nil, err
+		}
+		if ok && offset+items >= total {
+			return nil, nil
+		}
+		if !ok && p.config.Size > 0 && items < p.config.Size {
+			return nil, nil
+		}
+
+		if p.config.Type == PaginationPage {
+			page, _ := strconv.Atoi(query.Get(p.config.PageParam))
+			query.Set(p.config.PageParam, strconv.Itoa(page+1))
+		} else {
+			query.Set(p.config.OffsetParam, strconv.Itoa(offset+items))
+		}
+		next.RawQuery = query.Encode()
+
+		return &next, nil
+	case PaginationCursor:
+		document, err := decodeDocument(body)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.last != nil {
+			if last, ok := p.last.Lookup(document); ok && last == true {
+				return nil, nil
+			}
+		}
+
+		value, ok := p.cursor.Lookup(document)
+		if !ok || value == nil || stringValue(value) == "" {
+			return nil, nil
+		}
+		cursor := stringValue(value)
+
+		next := *current
+		query := next.Query()
+		query.Set(p.config.CursorParam, cursor)
+		next.RawQuery = query.Encode()
+
+		return &next, nil
+	case PaginationLink:
+		return nextLink(current, resp.Header)
+	default:
+		return nil, nil
+	}
+}
+
+// totalItems reads the total number of items of the feed from a response,
+// ok is false when the pager has no total_path or the response has no total
+func (p *pager) totalItems(body []byte) (int, bool, error) {
+	if p.total == nil {
+		return 0, false, nil
+	}
+
+	document, err := decodeDocument(body)
+	if err != nil {
+		return 0, false, err
+	}
+
+	value, ok := p.total.Lookup(document)
+	if !ok || value == nil {
+		return 0, false, nil
+	}
+
+	total, err := strconv.Atoi(stringValue(value))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid total %v", value)
+	}
+
+	return total, true, nil
+}
+
+// fetchChanges requests the pages of a feed one after another and hands every
+// response body to page, which returns the number of items it held. An
+// incremental feed is only asked for what changed since the cursor:
+// conditional headers are sent with the first page and a 304 response to it
+// ends the fetch.
+func fetchChanges(ctx context.Context, client *HTTPClient, p *pager, rawURL string, incremental IncrementalConfig, cursor Cursor, page func(body []byte) (int, error)) (Changes, error) {
+	var changes Changes
+
+	u, err := p.first(rawURL)
+	if err != nil {
+		return changes, err
+	}
+
+	start := time.Now()
+	full := true
+	if incremental.Enabled() {
+		interval := incremental.FullSyncInterval
+		if interval <= 0 {
+			interval = defaultFullSyncInterval
+		}
+		full = cursor.FullSyncAt == nil || start.Sub(*cursor.FullSyncAt) >= interval
+
+		if incremental.UpdatedSinceParam != "" && !full && cursor.UpdatedSince != nil {
+			query := u.Query()
+			query.Set(incremental.UpdatedSinceParam, cursor.UpdatedSince.UTC().Format(time.RFC3339))
+			u.RawQuery = query.Encode()
+			changes.Partial = true
+		}
+	}
+
+	next := cursor
+	for pages := 0; u != nil; pages++ {
+		if pages == p.config.MaxPages {
+			return changes, fmt.Errorf("stopped after %d pages", pages)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return changes, err
+		}
+
+		if pages == 0 && incremental.Conditional {
+			if cursor.ETag != "" {
+				req.Header.Set("If-None-Match", cursor.ETag)
+			}
+			if cursor.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cursor.LastModified)
+			}
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return changes, err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return changes, err
+		}
+
+		if pages == 0 && incremental.Conditional && resp.StatusCode == http.StatusNotModified {
+			changes.NotModified = true
+			changes.Cursor = &cursor
+
+			return changes, nil
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return changes, &StatusError{StatusCode: resp.StatusCode, Header: resp.Header}
+		}
+
+		if pages == 0 && incremental.Conditional {
+			next.ETag = resp.Header.Get("ETag")
+			next.LastModified = resp.Header.Get("Last-Modified")
+		}
+
+		items, err := page(body)
+		if err != nil {
+			if pages > 0 {
+				return changes, fmt.Errorf("page %d: %w", pages+1, err)
+			}
+
+			return changes, err
+		}
+
+		if u, err = p.next(u, resp, body, items); err != nil {
+			return changes, fmt.Errorf("page %d: %w", pages+1, err)
+		}
+	}
+
+	if incremental.Enabled() {
+		next.UpdatedSince = &start
+		if full {
+			next.FullSyncAt = &start
+		}
+		changes.Cursor = &next
+	}
+
+	return changes, nil
+}
+
+// StatusError is a response with an unexpected status code
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+}
+
+// nextLink resolves the rel="next" entry of the Link header against the
+// current page, nil when there is none. A link to another scheme or host is
+// refused, the credentials of the provider would be sent along.
+func nextLink(current *url.URL, header http.Header) (*url.URL, error) {
+	link := NextLink(header.Get("Link"))
+	if link == "" {
+		return nil, nil
+	}
+
+	next, err := current.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next link %q: %w", link, err)
+	}
+
+	if !strings.EqualFold(next.Scheme, current.Scheme) || !strings.EqualFold(next.Host, current.Host) {
+		return nil, fmt.Errorf("next link %q points to another host than %s://%s", link, current.Scheme, current.Host)
+	}
+
+	return next, nil
+}
+
+// NextLink returns the rel="next" url of a Link header, empty when there is none
+func NextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+				continue
+			}
+
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				if strings.EqualFold(rel, "next") {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"todo-planning/internal/model"
+)
+
+// pagedServer serves 5 tasks in pages of 2, understanding every pagination type
+func pagedServer(t *testing.T) *httptest.Server {
+	const total, size = 5, 2
+
+	task := func(id int) map[string]any {
+		return map[string]any{"id": strconv.Itoa(id), "level": 1, "hours": 2}
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		start := 0
+		switch {
+		case query.Has("page"):
+			page, _ := strconv.Atoi(query.Get("page"))
+			start = (page - 1) * size
+		case query.Has("offset"):
+			start, _ = strconv.Atoi(query.Get("offset"))
+		case query.Has("cursor"):
+			start, _ = strconv.Atoi(query.Get("cursor"))
+		case query.Has("from"):
+			start, _ = strconv.Atoi(query.Get("from"))
+		}
+
+		var items []map[string]any
+		for id := start + 1; id <= min(start+size, total); id++ {
+			items = append(items, task(id))
+		}
+
+		// cursor is handed out on every page, only last tells the end
+		response := map[string]any{"items": items, "cursor": strconv.Itoa(start + size), "last": start+size >= total}
+		if start+size < total {
+			response["next"] = strconv.Itoa(start + size)
+			w.Header().Set("Link", fmt.Sprintf(`<%s/tasks?from=%d>; rel="next", <%s/tasks?from=4>; rel="last"`, server.URL, start+size, server.URL))
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGenericClient_Pagination(t *testing.T) {
+	server := pagedServer(t)
+
+	tests := []struct {
+		name       string
+		pagination PaginationConfig
+		wantPages  int
+		wantTasks  int
+		wantErr    bool
+	}{
+		{name: "without pagination", wantPages: 1, wantTasks: 2},
+		{name: "page", pagination: PaginationConfig{Type: PaginationPage, Size: 2}, wantPages: 3, wantTasks: 5},
+		{name: "page until empty", pagination: PaginationConfig{Type: PaginationPage}, wantPages: 4, wantTasks: 5},
+		{name: "offset", pagination: PaginationConfig{Type: PaginationOffset, Size: 2}, wantPages: 3, wantTasks: 5},
+		{name: "cursor", pagination: PaginationConfig{Type: PaginationCursor, CursorPath: "$.next"}, wantPages: 3, wantTasks: 5},
+		{name: "cursor until last", pagination: PaginationConfig{Type: PaginationCursor, CursorPath: "$.cursor", LastPath: "$.last"}, wantPages: 3, wantTasks: 5},
+		{name: "link", pagination: PaginationConfig{Type: PaginationLink}, wantPages: 3, wantTasks: 5},
+		{name: "max pages", pagination: PaginationConfig{Type: PaginationLink, MaxPages: 2}, wantPages: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewGenericClient(GenericConfig{
+				Name:       "feed",
+				Url:        server.URL + "/tasks",
+				Items:      "$.items",
+				Fields:     GenericFields{ID: "$.id", Difficulty: "$.level", Duration: "$.hours"},
+				HTTP:       testHTTPConfig,
+				Pagination: tt.pagination,
+			})
+			if err != nil {
+				t.Fatalf("NewGenericClient() error = %v", err)
+			}
+
+			pages := 0
+			var ids []string
+			err = client.StreamTasks(context.Background(), func(tasks []model.Task) error {
+				pages++
+				for _, task := range tasks {
+					ids = append(ids, task.ExternalID)
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StreamTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if pages != tt.wantPages {
+				t.Errorf("Expected %d pages, got %d", tt.wantPages, pages)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(ids) != tt.wantTasks {
+				t.Fatalf("Expected %d tasks, got %v", tt.wantTasks, ids)
+			}
+			for i, id := range ids {
+				if id != strconv.Itoa(i+1) {
+					t.Errorf("Expected task %d to have id %d, got %s", i, i+1, id)
+				}
+			}
+		})
+	}
+}
+
+func TestGenericClient_LinkToOtherHost(t *testing.T) {
+	t.Setenv("TEST_FEED_TOKEN", "feed-token")
+
+	var leaked []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = append(leaked, r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]any{"items": []any{}})
+	}))
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer feed-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Link", fmt.Sprintf(`<%s/tasks?page=2>; rel="next"`, other.URL))
+		json.NewEncoder(w).Encode(map[string]any{"items": []any{map[string]any{"id": "1", "level": 1, "hours": 2}}})
+	}))
+	defer server.Close()
+
+	client, err := NewGenericClient(GenericConfig{
+		Name:       "feed",
+		Url:        server.URL + "/tasks",
+		Items:      "$.items",
+		Fields:     GenericFields{ID: "$.id", Difficulty: "$.level", Duration: "$.hours"},
+		HTTP:       testHTTPConfig,
+		Auth:       AuthConfig{Type: AuthBearer, Token: Secret{Env: "TEST_FEED_TOKEN"}},
+		Pagination: PaginationConfig{Type: PaginationLink},
+	})
+	if err != nil {
+		t.Fatalf("NewGenericClient() error = %v", err)
+	}
+
+	if _, err := client.FetchTasks(); err == nil {
+		t.Error("Expected an error for a next link to another host")
+	}
+	if len(leaked) != 0 {
+		t.Errorf("Expected no request to the other host, got %d with %v", len(leaked), leaked)
+	}
+}
+
+func TestMockOneClient_Pagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Write([]byte(`[{"id": 1, "value": 2, "estimated_duration": 3}, {"id": 2, "value": 2, "estimated_duration": 3}]`))
+		case "2":
+			w.Write([]byte(`[{"id": 3, "value": 2, "estimated_duration": 3}]`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New("mock-one", "paged", Options{
+		"url":        server.URL,
+		"pagination": map[string]any{"type": "page", "size": 2},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tasks, err := p.FetchTasks()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Errorf("Expected 3 tasks, got %d", len(tasks))
+	}
+}
+
+func TestNewPager_InvalidConfig(t *testing.T) {
+	configs := []PaginationConfig{
+		{Type: "pages"},
+		{Type: PaginationCursor},
+		{Type: PaginationCursor, CursorPath: "$.next["},
+		{Type: PaginationPage, Size: -1},
+		{Type: PaginationPage, TotalPath: "$.total"},
+		{Type: PaginationOffset, TotalPath: "$.total["},
+		{Type: PaginationOffset, LastPath: "$.last"},
+		{Type: PaginationCursor, CursorPath: "$.next", LastPath: "$.last["},
+	}
+
+	for _, config := range configs {
+		if _, err := newPager(config); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: `<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=5>; rel="last"`, expected: "https://api.example.com/items?page=2"},
+		{header: `<https://api.example.com/items?page=1>; rel="prev"`, expected: ""},
+		{header: `</items?page=3>; rel="prev next"`, expected: "/items?page=3"},
+		{header: `<https://api.example.com/items?page=2>; REL=next`, expected: "https://api.example.com/items?page=2"},
+		{header: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if got := NextLink(tt.header); got != tt.expected {
+			t.Errorf("NextLink(%q) = %q, want %q", tt.header, got, tt.expected)
+		}
+	}
+}
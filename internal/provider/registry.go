@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options holds the type specific settings of a provider instance as read
+// from config.yaml
+type Options map[string]any
+
+// Decode fills a struct with yaml tags from the options
+func (o Options) Decode(target any) error {
+	data, err := yaml.Marshal(map[string]any(o))
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+
+	return nil
+}
+
+// Factory builds a provider instance. The name identifies the instance and
+// is used as the source of its tasks.
+type Factory func(name string, options Options) (Provider, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a provider type available under the given name. It panics
+// when the type is registered twice.
+func Register(typeName string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[typeName]; ok {
+		panic(fmt.Sprintf("provider type %s is already registered", typeName))
+	}
+
+	registry[typeName] = factory
+}
+
+// New builds an instance of a registered provider type
+func New(typeName, name string, options Options) (Provider, error) {
+	registryMu.RLock()
+	factory, ok := registry[typeName]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown provider type %q", typeName)
+	}
+
+	if name == "" {
+		name = typeName
+	}
+
+	p, err := factory(name, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider %s of type %s: %w", name, typeName, err)
+	}
+
+	return p, nil
+}
+
+// Types returns the registered provider types
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	types := make([]string, 0, len(registry))
+	for typeName := range registry {
+		types = append(types, typeName)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// urlOptions are the options of providers that only need a url
+type urlOptions struct {
+	Url         string            `yaml:"url"`
+	HTTP        HTTPConfig        `yaml:"http"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Pagination  PaginationConfig  `yaml:"pagination"`
+	Incremental IncrementalConfig `yaml:"incremental"`
+}
+
+func init() {
+	Register("mock-one", func(name string, options Options) (Provider, error) {
+		opts := urlOptions{HTTP: DefaultHTTPConfig}
+		if err := options.Decode(&opts); err != nil {
+			return nil, err
+		}
+
+		httpClient, err := newProviderClient(opts.HTTP, opts.Auth)
+		if err != nil {
+			return nil, err
+		}
+
+		pager, err := newPager(opts.Pagination)
+		if err != nil {
+			return nil, err
+		}
+
+		client := NewMockOneClient(opts.Url)
+		client.name = name
+		client.client = httpClient
+		client.pager = pager
+		client.incremental = opts.Incremental
+
+		return client, nil
+	})
+
+	Register("mock-two", func(name string, options Options) (Provider, error) {
+		opts := urlOptions{HTTP: DefaultHTTPConfig}
+		if err := options.Decode(&opts); err != nil {
+			return nil, err
+		}
+
+		httpClient, err := newProviderClient(opts.HTTP, opts.Auth)
+		if err != nil {
+			return nil, err
+		}
+
+		pager, err := newPager(opts.Pagination)
+		if err != nil {
+			return nil, err
+		}
+
+		client := NewMockTwoClient(opts.Url)
+		client.name = name
+		client.client = httpClient
+		client.pager = pager
+		client.incremental = opts.Incremental
+
+		return client, nil
+	})
+
+	Register("generic", func(name string, options Options) (Provider, error) {
+		config := GenericConfig{HTTP: DefaultHTTPConfig}
+		if err := options.Decode(&config); err != nil {
+			return nil, err
+		}
+		config.Name = name
+
+		return NewGenericClient(config)
+	})
+
+	Register("github", func(name string, options Options) (Provider, error) {
+		config := GitHubConfig{HTTP: DefaultHTTPConfig}
+		if err := options.Decode(&config); err != nil {
+			return nil, err
+		}
+		config.Name = name
+
+		return NewGitHubClient(config)
+	})
+
+	Register("jira", func(name string, options Options) (Provider, error) {
+		config := JiraConfig{HTTP: DefaultHTTPConfig}
+		if err := options.Decode(&config); err != nil {
+			return nil, err
+		}
+		config.Name = name
+
+		return NewJiraClient(config)
+	})
+
+	Register("file", func(name string, options Options) (Provider, error) {
+		var config FileConfig
+		if err := options.Decode(&config); err != nil {
+			return nil, err
+		}
+		config.Name = name
+
+		return NewFileClient(config)
+	})
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo-planning/internal/model"
+)
+
+type staticProvider struct {
+	name string
+}
+
+func (s *staticProvider) FetchTasks() ([]model.Task, error) {
+	return nil, nil
+}
+
+func TestRegister(t *testing.T) {
+	Register("static-test", func(name string, options Options) (Provider, error) {
+		return &staticProvider{name: name}, nil
+	})
+
+	p, err := New("static-test", "", nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if static := p.(*staticProvider); static.name != "static-test" {
+		t.Errorf("Expected the type as default name, got '%s'", static.name)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic when registering a type twice")
+		}
+	}()
+	Register("static-test", func(name string, options Options) (Provider, error) {
+		return nil, nil
+	})
+}
+
+func TestNew(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1, "value": 2, "estimated_duration": 3}]`))
+	}))
+	defer server.Close()
+
+	p, err := New("mock-one", "team-a", Options{"url": server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if name := NameOf(p, 0); name != "team-a" {
+		t.Errorf("Expected name 'team-a', got '%s'", name)
+	}
+
+	tasks, err := p.FetchTasks()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Source != "team-a" {
+		t.Errorf("Expected one task with source 'team-a', got %+v", tasks)
+	}
+
+	if _, err := New("unknown", "unknown", nil); err == nil {
+		t.Error("Expected an error for an unknown type, got nil")
+	}
+
+	if _, err := New("mock-one", "invalid", Options{"url": []string{"not", "a", "url"}}); err == nil {
+		t.Error("Expected an error for invalid options, got nil")
+	}
+
+	for _, typeName := range []string{"generic", "mock-one", "mock-two"} {
+		found := false
+		for _, registered := range Types() {
+			found = found || registered == typeName
+		}
+
+		if !found {
+			t.Errorf("Expected type %s to be registered", typeName)
+		}
+	}
+}
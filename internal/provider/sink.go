@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"context"
+	"time"
+)
+
+// PlannedAssignment is the developer and week a plan gives to a task of a provider
+type PlannedAssignment struct {
+	ExternalID string
+	Developer  string    // name of the developer in the planner
+	Week       int       // plan week, starting at 1
+	WeekStart  time.Time // Monday of the calendar week the plan week falls in
+}
+
+// AssignmentSink is implemented by providers that can write planned
+// assignments back to their source, e.g. as assignee and milestone
+type AssignmentSink interface {
+	// PushAssignment writes an assignment to the source and describes what
+	// was changed. In a dry run nothing is written and the description tells
+	// what would be.
+	PushAssignment(ctx context.Context, assignment PlannedAssignment, dryRun bool) (string, error)
+}
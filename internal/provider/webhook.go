@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"todo-planning/internal/model"
+)
+
+// Webhook events
+const (
+	EventCreate = "create"
+	EventUpdate = "update"
+	EventDelete = "delete"
+)
+
+// WebhookProvider is implemented by providers whose source can push changes
+// instead of waiting for the next sync
+type WebhookProvider interface {
+	// ParseWebhook reads the tasks of an event in the native format of the
+	// source, a single task or a list of them. Only the external ids are
+	// needed for delete events.
+	ParseWebhook(event string, body []byte) ([]model.Task, error)
+}
+
+// webhookItems splits a webhook payload holding a single item or a list of
+// them into the items
+func webhookItems(body []byte) ([]json.RawMessage, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, fmt.Errorf("empty payload")
+	}
+
+	if body[0] != '[' {
+		return []json.RawMessage{body}, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
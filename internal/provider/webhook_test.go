@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestParseWebhook(t *testing.T) {
+	generic, err := NewGenericClient(GenericConfig{
+		Name:   "tracker",
+		Url:    "http://tracker.example.com",
+		Items:  "$.result.issues",
+		Fields: GenericFields{ID: "$.key", Difficulty: "$.meta.level", Duration: "$.estimate.minutes"},
+		Units:  GenericUnits{Duration: "minutes"},
+	})
+	if err != nil {
+		t.Fatalf("NewGenericClient() error = %v", err)
+	}
+
+	mockOne := NewMockOneClient("")
+	mockOne.name = "team-a"
+
+	tests := []struct {
+		name          string
+		provider      WebhookProvider
+		event         string
+		body          string
+		wantIDs       []string
+		wantDurations []float64
+		wantSource    string
+		wantErr       bool
+	}{
+		{
+			name:          "mock-one task",
+			provider:      mockOne,
+			event:         EventCreate,
+			body:          `{"id": 7, "value": 2, "estimated_duration": 3}`,
+			wantIDs:       []string{"7"},
+			wantDurations: []float64{3},
+			wantSource:    "team-a",
+		},
+		{
+			name:          "mock-two tasks",
+			provider:      NewMockTwoClient(""),
+			event:         EventUpdate,
+			body:          ` [{"id": 1, "zorluk": 1, "sure": 2}, {"id": 2, "zorluk": 3, "sure": 4}]`,
+			wantIDs:       []string{"1", "2"},
+			wantDurations: []float64{2, 4},
+			wantSource:    "mock-two",
+		},
+		{
+			name:          "generic document",
+			provider:      generic,
+			event:         EventCreate,
+			body:          `{"result": {"issues": [{"key": "PRJ-1", "meta": {"level": 2}, "estimate": {"minutes": 90}}]}}`,
+			wantIDs:       []string{"PRJ-1"},
+			wantDurations: []float64{1.5},
+			wantSource:    "tracker",
+		},
+		{
+			name:          "generic item",
+			provider:      generic,
+			event:         EventUpdate,
+			body:          `{"key": "PRJ-2", "meta": {"level": 1}, "estimate": {"minutes": 30}}`,
+			wantIDs:       []string{"PRJ-2"},
+			wantDurations: []float64{0.5},
+			wantSource:    "tracker",
+		},
+		{
+			name:          "generic delete with ids only",
+			provider:      generic,
+			event:         EventDelete,
+			body:          `[{"key": "PRJ-1"}, {"key": "PRJ-2"}]`,
+			wantIDs:       []string{"PRJ-1", "PRJ-2"},
+			wantDurations: []float64{0, 0},
+			wantSource:    "tracker",
+		},
+		{
+			name:     "generic update without estimates",
+			provider: generic,
+			event:    EventUpdate,
+			body:     `{"key": "PRJ-1"}`,
+			wantErr:  true,
+		},
+		{
+			name:     "empty payload",
+			provider: mockOne,
+			event:    EventCreate,
+			body:     " ",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := tt.provider.ParseWebhook(tt.event, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(tasks) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d tasks, got %d", len(tt.wantIDs), len(tasks))
+			}
+			for i, task := range tasks {
+				if task.ExternalID != tt.wantIDs[i] || task.EstimatedDuration != tt.wantDurations[i] || task.Source != tt.wantSource {
+					t.Errorf("Expected task %s of %s with duration %v, got %s of %s with %v",
+						tt.wantIDs[i], tt.wantSource, tt.wantDurations[i], task.ExternalID, task.Source, task.EstimatedDuration)
+				}
+			}
+		})
+	}
+}
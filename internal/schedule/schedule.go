@@ -0,0 +1,178 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the next time a job should run after the given time
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// Parse reads a schedule given either as a duration such as "30m" or as a
+// cron expression with the five fields minute, hour, day of month, month
+// and day of week, e.g. "*/15 8-18 * * 1-5"
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty schedule")
+	}
+
+	if interval, err := time.ParseDuration(spec); err == nil {
+		if interval <= 0 {
+			return nil, fmt.Errorf("schedule interval must be positive, got %s", spec)
+		}
+
+		return Every(interval), nil
+	}
+
+	return ParseCron(spec)
+}
+
+// Every runs a job in a fixed interval
+type Every time.Duration
+
+func (e Every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+// Cron runs a job at the minutes matching a cron expression
+type Cron struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64 // bit i is set when value i matches
+
+	// like cron, when both day fields are restricted a day matching either is used
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7}, // 0 and 7 are both sunday
+}
+
+// ParseCron reads a five field cron expression. Every field accepts "*",
+// single values, ranges "a-b", lists "a,b" and steps "*/n" or "a-b/n".
+func ParseCron(spec string) (*Cron, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q must have %d fields, got %d", spec, len(cronFields), len(fields))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseCronField(field, cronFields[i]); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+		}
+	}
+
+	// sunday may be written as 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &Cron{
+		minute:        bits[0],
+		hour:          bits[1],
+		dayOfMonth:    bits[2],
+		month:         bits[3],
+		dayOfWeek:     bits[4],
+		anyDayOfMonth: strings.HasPrefix(fields[2], "*"),
+		anyDayOfWeek:  strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if before, after, ok := strings.Cut(part, "/"); ok {
+			var err error
+			if step, err = strconv.Atoi(after); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", after, spec.name)
+			}
+			part = before
+		}
+
+		low, high := spec.min, spec.max
+		if part != "*" {
+			before, after, isRange := strings.Cut(part, "-")
+
+			var err error
+			if low, err = strconv.Atoi(before); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", before, spec.name)
+			}
+
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(after); err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s field", after, spec.name)
+				}
+			} else if step > 1 {
+				// "a/n" means from a to the end of the range
+				high = spec.max
+			}
+		}
+
+		if low < spec.min || high > spec.max || low > high {
+			return 0, fmt.Errorf("%s field %q out of range %d-%d", spec.name, part, spec.min, spec.max)
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+// Next returns the first matching minute after the given time
+func (c *Cron) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+
+	// every valid expression matches at least once within a few years
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c *Cron) matchesDay(t time.Time) bool {
+	dayOfMonth := c.dayOfMonth&(1<<t.Day()) != 0
+	dayOfWeek := c.dayOfWeek&(1<<int(t.Weekday())) != 0
+
+	switch {
+	case c.anyDayOfMonth && c.anyDayOfWeek:
+		return true
+	case c.anyDayOfMonth:
+		return dayOfWeek
+	case c.anyDayOfWeek:
+		return dayOfMonth
+	default:
+		return dayOfMonth || dayOfWeek
+	}
+}
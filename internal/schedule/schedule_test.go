@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	after := time.Date(2024, time.March, 15, 10, 7, 30, 0, time.UTC) // a friday
+
+	tests := []struct {
+		name     string
+		spec     string
+		expected time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "duration",
+			spec:     "30m",
+			expected: after.Add(30 * time.Minute),
+		},
+		{
+			name:     "every minute",
+			spec:     "* * * * *",
+			expected: time.Date(2024, time.March, 15, 10, 8, 0, 0, time.UTC),
+		},
+		{
+			name:     "step",
+			spec:     "*/15 * * * *",
+			expected: time.Date(2024, time.March, 15, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name:     "next day",
+			spec:     "0 9 * * *",
+			expected: time.Date(2024, time.March, 16, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekdays only",
+			spec:     "30 8-18/2 * * 1-5",
+			expected: time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "sunday as 7",
+			spec:     "0 0 * * 7",
+			expected: time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day of month or day of week",
+			spec:     "0 0 1 * 1",
+			expected: time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "list of months",
+			spec:     "0 0 1 1,7 *",
+			expected: time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "empty",
+			spec:    "",
+			wantErr: true,
+		},
+		{
+			name:    "negative duration",
+			spec:    "-5m",
+			wantErr: true,
+		},
+		{
+			name:    "too few fields",
+			spec:    "* * * *",
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			spec:    "60 * * * *",
+			wantErr: true,
+		},
+		{
+			name:    "invalid step",
+			spec:    "*/0 * * * *",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if next := schedule.Next(after); !next.Equal(tt.expected) {
+				t.Errorf("Parse(%q).Next() = %v, want %v", tt.spec, next, tt.expected)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"fmt"
+
 	"todo-planning/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AssignmentService struct {
@@ -26,3 +29,77 @@ func (s *AssignmentService) GetAssignments() ([]model.Assignment, error) {
 func (s *AssignmentService) CreateBatchAssignments(assignments []model.Assignment) error {
 	return s.db.CreateInBatches(&assignments, 100).Error
 }
+
+// CreatePlanRun stores a plan run with the next version number and links
+// its assignments to it. Tasks and developers referenced by the assignments
+// are not written back.
+func (s *AssignmentService) CreatePlanRun(run *model.PlanRun) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var version int
+		if err := tx.Unscoped().Model(&model.PlanRun{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+			return fmt.Errorf("failed to get plan run version: %w", err)
+		}
+
+		run.Version = version + 1
+		if err := tx.Omit(clause.Associations).Create(run).Error; err != nil {
+			return fmt.Errorf("failed to create plan run: %w", err)
+		}
+
+		if len(run.Assignments) == 0 {
+			return nil
+		}
+
+		for i := range run.Assignments {
+			run.Assignments[i].PlanRunID = &run.ID
+		}
+
+		if err := tx.Omit(clause.Associations).CreateInBatches(&run.Assignments, 100).Error; err != nil {
+			return fmt.Errorf("failed to create plan run assignments: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetPlanRuns returns all plan runs, newest first, without their snapshots and assignments
+func (s *AssignmentService) GetPlanRuns() ([]model.PlanRun, error) {
+	var runs []model.PlanRun
+	if err := s.db.Omit("task_snapshot", "developer_snapshot").Order("id DESC").Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get plan runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// GetPlanRun returns a plan run with its assignments
+func (s *AssignmentService) GetPlanRun(id uint) (*model.PlanRun, error) {
+	var run model.PlanRun
+	if err := s.preloadPlanRun(s.db).First(&run, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get plan run %d: %w", id, err)
+	}
+
+	return &run, nil
+}
+
+// GetLatestPlanRun returns the most recent plan run with its assignments
+func (s *AssignmentService) GetLatestPlanRun() (*model.PlanRun, error) {
+	var run model.PlanRun
+	if err := s.preloadPlanRun(s.db).Order("id DESC").First(&run).Error; err != nil {
+		return nil, fmt.Errorf("failed to get latest plan run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// preloadPlanRun loads assignments together with their tasks and developers,
+// including the ones deleted since the plan was made
+func (s *AssignmentService) preloadPlanRun(db *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+
+	return db.
+		Preload("Assignments").
+		Preload("Assignments.Task", unscoped).
+		Preload("Assignments.Developer", unscoped)
+}
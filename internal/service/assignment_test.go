@@ -1,11 +1,14 @@
 package service
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"todo-planning/internal/model"
 	"todo-planning/internal/utility"
+
+	"gorm.io/gorm"
 )
 
 func setupAssignmentTest(t *testing.T) (*AssignmentService, func()) {
@@ -249,3 +252,103 @@ func TestAssignmentService_CreateBatchAssignments(t *testing.T) {
 		})
 	}
 }
+
+func TestAssignmentService_CreatePlanRun(t *testing.T) {
+	db := utility.GetTestDB()
+	utility.AutoMigrate(&model.Assignment{}, &model.PlanRun{}, &model.Task{}, &model.Developer{})
+
+	service := NewAssignmentService(db)
+	defer func() {
+		utility.ClearTables()
+		utility.CloseTestDB()
+	}()
+
+	task := model.Task{ExternalID: "1", Source: "test", Difficulty: 2, EstimatedDuration: 3}
+	developer := model.Developer{Name: "Developer 1", Productivity: 2}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if err := db.Create(&developer).Error; err != nil {
+		t.Fatalf("Failed to create developer: %v", err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		run := &model.PlanRun{
+			Sorter:            "weight",
+			TaskCount:         1,
+			DeveloperCount:    1,
+			TotalHours:        3,
+			TotalWeeks:        1,
+			TaskSnapshot:      []model.Task{task},
+			DeveloperSnapshot: []model.Developer{developer},
+			Assignments: []model.Assignment{
+				{
+					TaskID:          task.ID,
+					DeveloperID:     developer.ID,
+					WeekNumber:      1,
+					CalculatedHours: 3,
+					Task:            task,
+					Developer:       developer,
+				},
+			},
+		}
+
+		if err := service.CreatePlanRun(run); err != nil {
+			t.Fatalf("AssignmentService.CreatePlanRun() error = %v", err)
+		}
+		if run.Version != i {
+			t.Errorf("Expected version %d, got %d", i, run.Version)
+		}
+		if run.Assignments[0].PlanRunID == nil || *run.Assignments[0].PlanRunID != run.ID {
+			t.Errorf("Expected assignment to be linked to plan run %d", run.ID)
+		}
+	}
+
+	var taskCount int64
+	if err := db.Model(&model.Task{}).Count(&taskCount).Error; err != nil {
+		t.Fatalf("Failed to count tasks: %v", err)
+	}
+	if taskCount != 1 {
+		t.Errorf("Expected plan runs not to create tasks, got %d tasks", taskCount)
+	}
+
+	runs, err := service.GetPlanRuns()
+	if err != nil {
+		t.Fatalf("AssignmentService.GetPlanRuns() error = %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("Expected 2 plan runs, got %d", len(runs))
+	}
+	if runs[0].Version != 2 {
+		t.Errorf("Expected newest plan run first, got version %d", runs[0].Version)
+	}
+
+	latest, err := service.GetLatestPlanRun()
+	if err != nil {
+		t.Fatalf("AssignmentService.GetLatestPlanRun() error = %v", err)
+	}
+	if latest.Version != 2 {
+		t.Errorf("Expected latest version 2, got %d", latest.Version)
+	}
+	if len(latest.Assignments) != 1 {
+		t.Fatalf("Expected 1 assignment, got %d", len(latest.Assignments))
+	}
+	if latest.Assignments[0].Task.ExternalID != "1" || latest.Assignments[0].Developer.Name != "Developer 1" {
+		t.Errorf("Expected assignment task and developer to be loaded, got %+v", latest.Assignments[0])
+	}
+	if len(latest.TaskSnapshot) != 1 || len(latest.DeveloperSnapshot) != 1 {
+		t.Errorf("Expected snapshots to be stored, got %d tasks and %d developers", len(latest.TaskSnapshot), len(latest.DeveloperSnapshot))
+	}
+
+	first, err := service.GetPlanRun(runs[1].ID)
+	if err != nil {
+		t.Fatalf("AssignmentService.GetPlanRun() error = %v", err)
+	}
+	if first.Version != 1 {
+		t.Errorf("Expected version 1, got %d", first.Version)
+	}
+
+	if _, err := service.GetPlanRun(999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected record not found error, got %v", err)
+	}
+}
@@ -1,12 +1,26 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"todo-planning/internal/model"
+	"todo-planning/internal/utility"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrInvalidAvailability = errors.New("invalid availability")
+	ErrInvalidDeveloper    = errors.New("invalid developer")
+	ErrDuplicateDeveloper  = errors.New("developer name already exists")
+)
+
+// DefaultWeeklyCapacity is the weekly capacity in hours of developers added
+// without one
+const DefaultWeeklyCapacity = 45
+
 type DeveloperService struct {
 	db *gorm.DB
 }
@@ -17,8 +31,168 @@ func NewDeveloperService(db *gorm.DB) *DeveloperService {
 
 func (s *DeveloperService) GetDevelopers() ([]model.Developer, error) {
 	var developers []model.Developer
-	if err := s.db.Find(&developers).Error; err != nil {
+	if err := s.db.Preload("Availability").Find(&developers).Error; err != nil {
 		return nil, fmt.Errorf("failed to get developers: %w", err)
 	}
 	return developers, nil
 }
+
+// GetDeveloper returns a developer with their availability overrides
+func (s *DeveloperService) GetDeveloper(id uint) (*model.Developer, error) {
+	var developer model.Developer
+	if err := s.db.Preload("Availability").First(&developer, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get developer %d: %w", id, err)
+	}
+
+	return &developer, nil
+}
+
+// CreateDeveloper validates and stores a new developer. A developer without
+// a weekly capacity gets DefaultWeeklyCapacity, a capacity of 0 is kept.
+func (s *DeveloperService) CreateDeveloper(developer *model.Developer) error {
+	if err := s.validateDeveloper(developer); err != nil {
+		return err
+	}
+
+	if developer.WeeklyCapacity == nil {
+		developer.WeeklyCapacity = utility.ToPointer(float64(DefaultWeeklyCapacity))
+	}
+
+	if err := s.db.Omit(clause.Associations).Create(developer).Error; err != nil {
+		return fmt.Errorf("failed to create developer: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDeveloper replaces the name, productivity and weekly capacity of an
+// existing developer. A developer without a weekly capacity keeps the
+// current one.
+func (s *DeveloperService) UpdateDeveloper(developer *model.Developer) error {
+	var current model.Developer
+	if err := s.db.Select("id", "weekly_capacity").First(&current, developer.ID).Error; err != nil {
+		return fmt.Errorf("failed to get developer %d: %w", developer.ID, err)
+	}
+
+	if err := s.validateDeveloper(developer); err != nil {
+		return err
+	}
+
+	if developer.WeeklyCapacity == nil {
+		developer.WeeklyCapacity = current.WeeklyCapacity
+	}
+
+	err := s.db.Model(&model.Developer{ID: developer.ID}).
+		Select("name", "productivity", "weekly_capacity").
+		Updates(developer).Error
+	if err != nil {
+		return fmt.Errorf("failed to update developer %d: %w", developer.ID, err)
+	}
+
+	if err := s.db.Preload("Availability").First(developer, developer.ID).Error; err != nil {
+		return fmt.Errorf("failed to get developer %d: %w", developer.ID, err)
+	}
+
+	return nil
+}
+
+// DeleteDeveloper soft deletes a developer so they are no longer planned,
+// stored plan runs keep referring to them
+func (s *DeveloperService) DeleteDeveloper(id uint) error {
+	result := s.db.Delete(&model.Developer{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete developer %d: %w", id, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to delete developer %d: %w", id, gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// validateDeveloper checks the fields of a developer and that no other
+// active developer has the same name
+func (s *DeveloperService) validateDeveloper(developer *model.Developer) error {
+	developer.Name = strings.TrimSpace(developer.Name)
+	if developer.Name == "" {
+		return fmt.Errorf("%w: name must not be empty", ErrInvalidDeveloper)
+	}
+
+	if developer.Productivity < 0 {
+		return fmt.Errorf("%w: productivity must not be negative", ErrInvalidDeveloper)
+	}
+
+	if developer.WeeklyCapacity != nil && *developer.WeeklyCapacity < 0 {
+		return fmt.Errorf("%w: weekly capacity must not be negative", ErrInvalidDeveloper)
+	}
+
+	var count int64
+	err := s.db.Model(&model.Developer{}).
+		Where("name = ? AND id <> ?", developer.Name, developer.ID).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to check developer name: %w", err)
+	}
+
+	if count > 0 {
+		return fmt.Errorf("%w: %s", ErrDuplicateDeveloper, developer.Name)
+	}
+
+	return nil
+}
+
+// GetAvailability returns the week specific capacity overrides of a developer
+func (s *DeveloperService) GetAvailability(developerID uint) ([]model.DeveloperAvailability, error) {
+	if err := s.db.Select("id").First(&model.Developer{}, developerID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get developer %d: %w", developerID, err)
+	}
+
+	var availability []model.DeveloperAvailability
+	if err := s.db.Where("developer_id = ?", developerID).Order("week_number").Find(&availability).Error; err != nil {
+		return nil, fmt.Errorf("failed to get developer availability: %w", err)
+	}
+
+	return availability, nil
+}
+
+// SetAvailability sets the hours a developer can work in a plan week,
+// replacing an earlier override for the same week
+func (s *DeveloperService) SetAvailability(availability *model.DeveloperAvailability) error {
+	if availability.WeekNumber < 1 {
+		return fmt.Errorf("%w: week number must be at least 1", ErrInvalidAvailability)
+	}
+
+	if availability.Hours < 0 {
+		return fmt.Errorf("%w: hours must not be negative", ErrInvalidAvailability)
+	}
+
+	if err := s.db.Select("id").First(&model.Developer{}, availability.DeveloperID).Error; err != nil {
+		return fmt.Errorf("failed to get developer %d: %w", availability.DeveloperID, err)
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "developer_id"}, {Name: "week_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hours", "reason", "updated_at"}),
+	}).Create(availability).Error
+	if err != nil {
+		return fmt.Errorf("failed to set developer availability: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveAvailability removes the override of a plan week so the developer's
+// regular weekly capacity applies again
+func (s *DeveloperService) RemoveAvailability(developerID uint, weekNumber int) error {
+	result := s.db.Where("developer_id = ? AND week_number = ?", developerID, weekNumber).Delete(&model.DeveloperAvailability{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove developer availability: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to remove availability of developer %d in week %d: %w", developerID, weekNumber, gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
@@ -1,16 +1,19 @@
 package service
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"todo-planning/internal/model"
 	"todo-planning/internal/utility"
+
+	"gorm.io/gorm"
 )
 
 func setupDeveloperTest(t *testing.T) (*DeveloperService, func()) {
 	db := utility.GetTestDB()
-	utility.AutoMigrate(&model.Developer{})
+	utility.AutoMigrate(&model.Developer{}, &model.DeveloperAvailability{})
 
 	service := NewDeveloperService(db)
 
@@ -68,3 +71,176 @@ func TestDeveloperService_GetDevelopers(t *testing.T) {
 		}
 	}
 }
+
+func TestDeveloperService_SetAvailability(t *testing.T) {
+	service, cleanup := setupDeveloperTest(t)
+	defer cleanup()
+
+	developer := model.Developer{Name: "Developer 1", Productivity: 1.0, WeeklyCapacity: utility.ToPointer(30.0)}
+	if err := service.db.Create(&developer).Error; err != nil {
+		t.Fatalf("Failed to create developer: %v", err)
+	}
+
+	if err := service.SetAvailability(&model.DeveloperAvailability{DeveloperID: developer.ID, WeekNumber: 0, Hours: 10}); !errors.Is(err, ErrInvalidAvailability) {
+		t.Errorf("DeveloperService.SetAvailability() error = %v, want %v", err, ErrInvalidAvailability)
+	}
+	if err := service.SetAvailability(&model.DeveloperAvailability{DeveloperID: developer.ID, WeekNumber: 1, Hours: -1}); !errors.Is(err, ErrInvalidAvailability) {
+		t.Errorf("DeveloperService.SetAvailability() error = %v, want %v", err, ErrInvalidAvailability)
+	}
+	if err := service.SetAvailability(&model.DeveloperAvailability{DeveloperID: developer.ID + 1, WeekNumber: 1, Hours: 10}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeveloperService.SetAvailability() error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+
+	// setting the same week twice replaces the override
+	if err := service.SetAvailability(&model.DeveloperAvailability{DeveloperID: developer.ID, WeekNumber: 2, Hours: 0, Reason: "vacation"}); err != nil {
+		t.Fatalf("DeveloperService.SetAvailability() error = %v", err)
+	}
+	if err := service.SetAvailability(&model.DeveloperAvailability{DeveloperID: developer.ID, WeekNumber: 2, Hours: 20, Reason: "part-time"}); err != nil {
+		t.Fatalf("DeveloperService.SetAvailability() error = %v", err)
+	}
+
+	got, err := service.GetDevelopers()
+	if err != nil {
+		t.Fatalf("DeveloperService.GetDevelopers() error = %v", err)
+	}
+	if len(got) != 1 || len(got[0].Availability) != 1 {
+		t.Fatalf("DeveloperService.GetDevelopers() got = %+v, want one developer with one availability", got)
+	}
+	if got[0].WeeklyCapacity == nil || *got[0].WeeklyCapacity != 30 {
+		t.Errorf("DeveloperService.GetDevelopers() got.WeeklyCapacity = %v, want 30", got[0].WeeklyCapacity)
+	}
+	if availability := got[0].Availability[0]; availability.Hours != 20 || availability.Reason != "part-time" {
+		t.Errorf("DeveloperService.GetDevelopers() got.Availability = %+v, want 20 hours part-time", availability)
+	}
+
+	if err := service.RemoveAvailability(developer.ID, 2); err != nil {
+		t.Fatalf("DeveloperService.RemoveAvailability() error = %v", err)
+	}
+	if err := service.RemoveAvailability(developer.ID, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeveloperService.RemoveAvailability() error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
+
+func TestDeveloperService_CreateDeveloper(t *testing.T) {
+	service, cleanup := setupDeveloperTest(t)
+	defer cleanup()
+
+	developer := &model.Developer{Name: " Developer 1 ", Productivity: 1.5}
+	if err := service.CreateDeveloper(developer); err != nil {
+		t.Fatalf("DeveloperService.CreateDeveloper() error = %v", err)
+	}
+
+	if developer.ID == 0 || developer.Name != "Developer 1" {
+		t.Errorf("DeveloperService.CreateDeveloper() got = %+v, want stored developer named Developer 1", developer)
+	}
+
+	got, err := service.GetDeveloper(developer.ID)
+	if err != nil {
+		t.Fatalf("DeveloperService.GetDeveloper() error = %v", err)
+	}
+	if got.WeeklyCapacity == nil || *got.WeeklyCapacity != DefaultWeeklyCapacity {
+		t.Errorf("DeveloperService.GetDeveloper() got.WeeklyCapacity = %v, want default %v", got.WeeklyCapacity, DefaultWeeklyCapacity)
+	}
+
+	// a developer who gets no planned work
+	idle := &model.Developer{Name: "Developer 3", Productivity: 1, WeeklyCapacity: utility.ToPointer(0.0)}
+	if err := service.CreateDeveloper(idle); err != nil {
+		t.Fatalf("DeveloperService.CreateDeveloper() error = %v", err)
+	}
+	if got, err := service.GetDeveloper(idle.ID); err != nil || got.WeeklyCapacity == nil || *got.WeeklyCapacity != 0 {
+		t.Errorf("DeveloperService.GetDeveloper() got = %+v, %v, want weekly capacity 0", got, err)
+	}
+
+	tests := []struct {
+		name      string
+		developer *model.Developer
+		wantErr   error
+	}{
+		{
+			name:      "empty name",
+			developer: &model.Developer{Name: " ", Productivity: 1},
+			wantErr:   ErrInvalidDeveloper,
+		},
+		{
+			name:      "negative productivity",
+			developer: &model.Developer{Name: "Developer 2", Productivity: -1},
+			wantErr:   ErrInvalidDeveloper,
+		},
+		{
+			name:      "negative weekly capacity",
+			developer: &model.Developer{Name: "Developer 2", Productivity: 1, WeeklyCapacity: utility.ToPointer(-5.0)},
+			wantErr:   ErrInvalidDeveloper,
+		},
+		{
+			name:      "duplicate name",
+			developer: &model.Developer{Name: "Developer 1", Productivity: 2},
+			wantErr:   ErrDuplicateDeveloper,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.CreateDeveloper(tt.developer); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeveloperService.CreateDeveloper() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeveloperService_UpdateDeleteDeveloper(t *testing.T) {
+	service, cleanup := setupDeveloperTest(t)
+	defer cleanup()
+
+	first := &model.Developer{Name: "Developer 1", Productivity: 1, WeeklyCapacity: utility.ToPointer(30.0)}
+	second := &model.Developer{Name: "Developer 2", Productivity: 2}
+	for _, developer := range []*model.Developer{first, second} {
+		if err := service.CreateDeveloper(developer); err != nil {
+			t.Fatalf("DeveloperService.CreateDeveloper() error = %v", err)
+		}
+	}
+
+	// keeping the own name is fine, taking another developer's is not
+	update := &model.Developer{ID: first.ID, Name: "Developer 1", Productivity: 3}
+	if err := service.UpdateDeveloper(update); err != nil {
+		t.Fatalf("DeveloperService.UpdateDeveloper() error = %v", err)
+	}
+	if update.Productivity != 3 || update.WeeklyCapacity == nil || *update.WeeklyCapacity != 30 {
+		t.Errorf("DeveloperService.UpdateDeveloper() got = %+v, want productivity 3 and weekly capacity 30", update)
+	}
+
+	// the weekly capacity can be taken away
+	update = &model.Developer{ID: first.ID, Name: "Developer 1", Productivity: 3, WeeklyCapacity: utility.ToPointer(0.0)}
+	if err := service.UpdateDeveloper(update); err != nil {
+		t.Fatalf("DeveloperService.UpdateDeveloper() error = %v", err)
+	}
+	if update.WeeklyCapacity == nil || *update.WeeklyCapacity != 0 {
+		t.Errorf("DeveloperService.UpdateDeveloper() got = %+v, want weekly capacity 0", update)
+	}
+
+	if err := service.UpdateDeveloper(&model.Developer{ID: first.ID, Name: "Developer 2", Productivity: 1}); !errors.Is(err, ErrDuplicateDeveloper) {
+		t.Errorf("DeveloperService.UpdateDeveloper() error = %v, want %v", err, ErrDuplicateDeveloper)
+	}
+	if err := service.UpdateDeveloper(&model.Developer{ID: second.ID + 1, Name: "Developer 3", Productivity: 1}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeveloperService.UpdateDeveloper() error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+
+	if err := service.DeleteDeveloper(second.ID); err != nil {
+		t.Fatalf("DeveloperService.DeleteDeveloper() error = %v", err)
+	}
+	if err := service.DeleteDeveloper(second.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeveloperService.DeleteDeveloper() error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+
+	developers, err := service.GetDevelopers()
+	if err != nil {
+		t.Fatalf("DeveloperService.GetDevelopers() error = %v", err)
+	}
+	if len(developers) != 1 || developers[0].ID != first.ID {
+		t.Errorf("DeveloperService.GetDevelopers() got = %+v, want only developer %d", developers, first.ID)
+	}
+
+	// the name of a deleted developer can be used again
+	if err := service.CreateDeveloper(&model.Developer{Name: "Developer 2", Productivity: 2}); err != nil {
+		t.Errorf("DeveloperService.CreateDeveloper() error = %v", err)
+	}
+}
@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"todo-planning/internal/config"
 	"todo-planning/internal/logger"
@@ -9,44 +13,293 @@ import (
 	"todo-planning/internal/provider"
 )
 
+// FetchOptions control how the tasks of a single provider are fetched
+type FetchOptions struct {
+	Timeout    time.Duration // limit of a single attempt
+	Retries    int           // attempts after the first failed one
+	RetryDelay time.Duration // pause between attempts
+}
+
+// DefaultFetchOptions apply to providers without options of their own
+var DefaultFetchOptions = FetchOptions{
+	Timeout:    30 * time.Second,
+	Retries:    0,
+	RetryDelay: time.Second,
+}
+
 type ProviderService struct {
 	providers []provider.Provider
+	options   map[string]FetchOptions // by provider name
 }
 
 func NewProviderService() *ProviderService {
+	providers, options := InitProviders()
+
 	return &ProviderService{
-		providers: InitProviders(),
+		providers: providers,
+		options:   options,
+	}
+}
+
+// Provider returns the provider with the given name, nil when there is none
+func (s *ProviderService) Provider(name string) provider.Provider {
+	for i, p := range s.providers {
+		if provider.NameOf(p, i) == name {
+			return p
+		}
 	}
+
+	return nil
 }
 
-// FetchTasksFromProviders fetches tasks from all providers
+// ProviderResult holds the tasks fetched from a single provider
+type ProviderResult struct {
+	Provider string
+	Source   string       // source of the tasks, empty when the provider doesn't report a name
+	Tasks    []model.Task // empty when the tasks were streamed
+	Fetched  int
+
+	// Cursor is where the next incremental fetch continues from, nil when
+	// the provider doesn't fetch incrementally
+	Cursor      *provider.Cursor
+	NotModified bool // nothing changed since the cursor
+	Partial     bool // only the tasks changed since the cursor were fetched
+
+	Err      error
+	Latency  time.Duration
+	Attempts int
+}
+
+// FetchTasksFromProviders fetches tasks from all providers. It only fails
+// when every provider failed.
 func (s *ProviderService) FetchTasksFromProviders() ([]model.Task, error) {
 	var allTasks []model.Task
+	var errs []error
 
-	for _, p := range s.providers {
-		tasks, err := p.FetchTasks()
-		if err != nil {
-			logger.Error(fmt.Errorf("failed to fetch tasks from provider: %w", err))
+	results := s.FetchResults(context.Background())
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("provider %s: %w", result.Provider, result.Err))
 			continue
 		}
 
-		allTasks = append(allTasks, tasks...)
+		allTasks = append(allTasks, result.Tasks...)
+	}
+
+	if len(results) > 0 && len(errs) == len(results) {
+		return nil, errors.Join(errs...)
 	}
 
 	return allTasks, nil
 }
 
-// InitProviders returns a slice of Provider instances
-// this behavior is not ideal, but it's a simple example
-// to demonstrate the concept
-func InitProviders() []provider.Provider {
+// FetchResults fetches tasks from all providers concurrently and reports the
+// outcome of every provider separately, in the order of the providers
+func (s *ProviderService) FetchResults(ctx context.Context) []ProviderResult {
+	return s.StreamResults(ctx, nil, nil, nil)
+}
+
+// StreamResults fetches tasks from all providers concurrently like
+// FetchResults, but hands them to handle page by page instead of collecting
+// them. start and handle are called with the index of the provider from one
+// goroutine per provider. A retried fetch hands its pages over again, so
+// start is called before every attempt to drop what the failed one handed
+// over. An error of handle stops the fetch without retrying it. Incremental
+// providers only fetch what changed since their cursor, keyed by provider name.
+func (s *ProviderService) StreamResults(ctx context.Context, cursors map[string]provider.Cursor, start func(index int), handle func(index int, tasks []model.Task) error) []ProviderResult {
+	results := make([]ProviderResult, len(s.providers))
+
+	var wg sync.WaitGroup
+	for i, p := range s.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.fetch(ctx, i, p, cursors, start, handle)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// handlerError is an error of the page handler, which isn't retried
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string {
+	return e.err.Error()
+}
+
+func (e *handlerError) Unwrap() error {
+	return e.err
+}
+
+// fetch fetches the tasks of a provider, retrying failed attempts. The tasks
+// are collected when handle is nil.
+func (s *ProviderService) fetch(ctx context.Context, index int, p provider.Provider, cursors map[string]provider.Cursor, start func(int), handle func(int, []model.Task) error) ProviderResult {
+	result := ProviderResult{Provider: provider.NameOf(p, index)}
+	if named, ok := p.(provider.Named); ok {
+		result.Source = named.Name()
+	}
+
+	options := s.fetchOptions(result.Provider)
+	began := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		result.Attempts = attempt + 1
+		result.Tasks, result.Fetched = nil, 0
+		if start != nil {
+			start(index)
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, options.Timeout)
+		changes, err := provider.StreamChanges(attemptCtx, p, cursors[result.Provider], func(tasks []model.Task) error {
+			result.Fetched += len(tasks)
+			if handle == nil {
+				result.Tasks = append(result.Tasks, tasks...)
+				return nil
+			}
+
+			if err := handle(index, tasks); err != nil {
+				return &handlerError{err: err}
+			}
+
+			return nil
+		})
+		cancel()
+
+		result.Err = err
+		result.Cursor, result.NotModified, result.Partial = changes.Cursor, changes.NotModified, changes.Partial
+
+		var handleErr *handlerError
+		if errors.As(result.Err, &handleErr) {
+			result.Err = handleErr.err
+			break
+		}
+
+		if result.Err != nil && ctx.Err() == nil && errors.Is(result.Err, context.DeadlineExceeded) {
+			result.Err = fmt.Errorf("timed out after %s: %w", options.Timeout, result.Err)
+		}
+
+		if result.Err == nil || attempt >= options.Retries || ctx.Err() != nil {
+			break
+		}
+
+		logger.Info("fetching tasks from provider ", result.Provider, " failed, retrying: ", result.Err)
+
+		timer := time.NewTimer(options.RetryDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+
+	result.Latency = time.Since(began)
+
+	if result.Err != nil {
+		logger.Error(fmt.Errorf("failed to fetch tasks from provider %s: %w", result.Provider, result.Err))
+		result.Tasks = nil
+	}
+
+	return result
+}
+
+func (s *ProviderService) fetchOptions(name string) FetchOptions {
+	if options, ok := s.options[name]; ok {
+		return options
+	}
+
+	return DefaultFetchOptions
+}
+
+// InitProviders builds the providers configured in config.yaml together with
+// their fetch options. The providers list is used when present, otherwise
+// the mock-one, mock-two and generic entries of the provider section.
+func InitProviders() ([]provider.Provider, map[string]FetchOptions) {
 	config, err := config.Load()
 	if err != nil {
 		logger.Error(err)
+		return nil, nil
 	}
 
-	return []provider.Provider{
-		provider.NewMockOneClient(config.ProviderConfig.MockOne.Url),
-		provider.NewMockTwoClient(config.ProviderConfig.MockTwo.Url),
+	if len(config.Providers) == 0 {
+		return legacyProviders(config.ProviderConfig), nil
 	}
+
+	return newProviders(config.Providers)
+}
+
+// newProviders builds the enabled provider instances through the registry,
+// skipping the ones that can't be built
+func newProviders(instances []config.ProviderInstanceConfig) ([]provider.Provider, map[string]FetchOptions) {
+	providers := make([]provider.Provider, 0, len(instances))
+	options := make(map[string]FetchOptions, len(instances))
+	names := make(map[string]struct{}, len(instances))
+	for _, instance := range instances {
+		name := instance.Name
+		if name == "" {
+			name = instance.Type
+		}
+
+		if !instance.IsEnabled() {
+			logger.Info("provider ", name, " is disabled")
+			continue
+		}
+
+		if _, ok := names[name]; ok {
+			logger.Error(fmt.Errorf("provider %s is configured twice, names must be unique", name))
+			continue
+		}
+
+		p, err := provider.New(instance.Type, name, instance.Options)
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+
+		names[name] = struct{}{}
+		providers = append(providers, p)
+		options[name] = fetchOptions(instance)
+	}
+
+	return providers, options
+}
+
+// fetchOptions returns the fetch options of an instance, filling the ones it
+// doesn't set with the defaults
+func fetchOptions(instance config.ProviderInstanceConfig) FetchOptions {
+	options := DefaultFetchOptions
+	if instance.Timeout > 0 {
+		options.Timeout = instance.Timeout
+	}
+	if instance.Retries > 0 {
+		options.Retries = instance.Retries
+	}
+	if instance.RetryDelay > 0 {
+		options.RetryDelay = instance.RetryDelay
+	}
+
+	return options
+}
+
+// legacyProviders builds the providers of the fixed provider section
+func legacyProviders(providerConfig config.ProviderConfig) []provider.Provider {
+	providers := []provider.Provider{
+		provider.NewMockOneClient(providerConfig.MockOne.Url),
+		provider.NewMockTwoClient(providerConfig.MockTwo.Url),
+	}
+
+	for _, genericConfig := range providerConfig.Generic {
+		client, err := provider.NewGenericClient(genericConfig)
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+
+		providers = append(providers, client)
+	}
+
+	return providers
 }
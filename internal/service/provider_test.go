@@ -1,11 +1,16 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"todo-planning/internal/config"
 	"todo-planning/internal/model"
 	"todo-planning/internal/provider"
 	"todo-planning/internal/utility"
@@ -34,6 +39,22 @@ func serveMockProvider2(port string) {
 
 }
 
+// waitForPort waits until the mock provider server accepts connections
+func waitForPort(t *testing.T, port string) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		conn, err := net.Dial("tcp", "127.0.0.1:"+port)
+		if err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("mock provider server on port %s didn't start", port)
+}
+
 type mockProviderClient2 struct {
 	port string
 }
@@ -133,6 +154,15 @@ func TestProviderService_FetchTasksFromProviders(t *testing.T) {
 			wantTasks: 1,
 			wantErr:   false,
 		},
+		{
+			name: "every provider fails",
+			providers: []provider.Provider{
+				&mockProvider{err: errors.New("provider error")},
+				&mockProvider{err: errors.New("provider error")},
+			},
+			wantTasks: 0,
+			wantErr:   true,
+		},
 		{
 			name: "multiple providers with mock provider client 2",
 			providers: []provider.Provider{
@@ -146,6 +176,8 @@ func TestProviderService_FetchTasksFromProviders(t *testing.T) {
 
 	go serveMockProvider2("8081")
 	go serveMockProvider2("8082")
+	waitForPort(t, "8081")
+	waitForPort(t, "8082")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a new service with mock providers
@@ -167,3 +199,195 @@ func TestProviderService_FetchTasksFromProviders(t *testing.T) {
 		})
 	}
 }
+
+func TestNewProviders(t *testing.T) {
+	disabled := false
+
+	providers, options := newProviders([]config.ProviderInstanceConfig{
+		{Type: "mock-one", Name: "team-a", Options: map[string]any{"url": "http://team-a"}, Timeout: 5 * time.Second, Retries: 2},
+		{Type: "mock-one", Name: "team-b", Options: map[string]any{"url": "http://team-b"}},
+		{Type: "mock-two", Options: map[string]any{"url": "http://mock-two"}},
+		{Type: "mock-two", Name: "disabled", Enabled: &disabled},
+		{Type: "mock-one", Name: "team-a"},
+		{Type: "unknown", Name: "unknown"},
+		{Type: "generic", Name: "broken", Options: map[string]any{"url": "http://feed"}},
+		{Type: "generic", Name: "feed", Options: map[string]any{
+			"url":    "http://feed",
+			"fields": map[string]any{"id": "$.id", "difficulty": "$.level", "duration": "$.hours"},
+		}},
+	})
+
+	var names []string
+	for i, p := range providers {
+		names = append(names, provider.NameOf(p, i))
+	}
+
+	expected := []string{"team-a", "team-b", "mock-two", "feed"}
+	if len(names) != len(expected) {
+		t.Fatalf("newProviders() got = %v, want %v", names, expected)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("newProviders() got[%d] = %v, want %v", i, names[i], expected[i])
+		}
+	}
+
+	want := FetchOptions{Timeout: 5 * time.Second, Retries: 2, RetryDelay: DefaultFetchOptions.RetryDelay}
+	if options["team-a"] != want {
+		t.Errorf("newProviders() got options = %+v, want %+v", options["team-a"], want)
+	}
+	if options["team-b"] != DefaultFetchOptions {
+		t.Errorf("newProviders() got options = %+v, want %+v", options["team-b"], DefaultFetchOptions)
+	}
+}
+
+// slowProvider returns its tasks after a delay unless the context ends first
+type slowProvider struct {
+	name  string
+	delay time.Duration
+	tasks []model.Task
+	calls atomic.Int32
+	fails int32 // number of calls failing before the first success
+}
+
+func (p *slowProvider) Name() string {
+	return p.name
+}
+
+func (p *slowProvider) FetchTasks() ([]model.Task, error) {
+	return p.FetchTasksContext(context.Background())
+}
+
+func (p *slowProvider) FetchTasksContext(ctx context.Context) ([]model.Task, error) {
+	if p.calls.Add(1) <= p.fails {
+		return nil, errors.New("provider error")
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(p.delay):
+		return p.tasks, nil
+	}
+}
+
+func TestProviderService_FetchResults(t *testing.T) {
+	tasks := []model.Task{{ExternalID: "1", Name: utility.ToPointer("Task 1")}}
+
+	tests := []struct {
+		name         string
+		providers    []*slowProvider
+		options      map[string]FetchOptions
+		wantErrors   []bool
+		wantAttempts []int
+		maxDuration  time.Duration
+	}{
+		{
+			name: "providers are fetched concurrently",
+			providers: []*slowProvider{
+				{name: "a", delay: 100 * time.Millisecond, tasks: tasks},
+				{name: "b", delay: 100 * time.Millisecond, tasks: tasks},
+				{name: "c", delay: 100 * time.Millisecond, tasks: tasks},
+			},
+			wantErrors:   []bool{false, false, false},
+			wantAttempts: []int{1, 1, 1},
+			maxDuration:  250 * time.Millisecond,
+		},
+		{
+			name: "slow provider times out",
+			providers: []*slowProvider{
+				{name: "slow", delay: time.Second, tasks: tasks},
+				{name: "fast", tasks: tasks},
+			},
+			options: map[string]FetchOptions{
+				"slow": {Timeout: 50 * time.Millisecond},
+			},
+			wantErrors:   []bool{true, false},
+			wantAttempts: []int{1, 1},
+			maxDuration:  500 * time.Millisecond,
+		},
+		{
+			name: "failed fetch is retried",
+			providers: []*slowProvider{
+				{name: "flaky", fails: 2, tasks: tasks},
+				{name: "broken", fails: 10, tasks: tasks},
+			},
+			options: map[string]FetchOptions{
+				"flaky":  {Timeout: time.Second, Retries: 2, RetryDelay: 10 * time.Millisecond},
+				"broken": {Timeout: time.Second, Retries: 1, RetryDelay: 10 * time.Millisecond},
+			},
+			wantErrors:   []bool{false, true},
+			wantAttempts: []int{3, 2},
+			maxDuration:  500 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &ProviderService{options: tt.options}
+			for _, p := range tt.providers {
+				service.providers = append(service.providers, p)
+			}
+
+			start := time.Now()
+			results := service.FetchResults(context.Background())
+			if elapsed := time.Since(start); elapsed > tt.maxDuration {
+				t.Errorf("ProviderService.FetchResults() took %v, want at most %v", elapsed, tt.maxDuration)
+			}
+
+			if len(results) != len(tt.providers) {
+				t.Fatalf("ProviderService.FetchResults() got %v results, want %v", len(results), len(tt.providers))
+			}
+			for i, result := range results {
+				if result.Provider != tt.providers[i].name {
+					t.Errorf("ProviderService.FetchResults() got[%d].Provider = %v, want %v", i, result.Provider, tt.providers[i].name)
+				}
+				if (result.Err != nil) != tt.wantErrors[i] {
+					t.Errorf("ProviderService.FetchResults() got[%d].Err = %v, want error %v", i, result.Err, tt.wantErrors[i])
+				}
+				if result.Attempts != tt.wantAttempts[i] {
+					t.Errorf("ProviderService.FetchResults() got[%d].Attempts = %v, want %v", i, result.Attempts, tt.wantAttempts[i])
+				}
+				if result.Err == nil && len(result.Tasks) != len(tasks) {
+					t.Errorf("ProviderService.FetchResults() got[%d] = %v tasks, want %v", i, len(result.Tasks), len(tasks))
+				}
+				if result.Latency <= 0 {
+					t.Errorf("ProviderService.FetchResults() got[%d].Latency = %v, want a positive latency", i, result.Latency)
+				}
+			}
+		})
+	}
+}
+
+func TestProviderService_FetchResultsCancel(t *testing.T) {
+	blocking := &blockingProvider{release: make(chan struct{})}
+	defer close(blocking.release)
+
+	service := &ProviderService{
+		providers: []provider.Provider{
+			&slowProvider{name: "slow", delay: 10 * time.Second},
+			blocking,
+		},
+		options: map[string]FetchOptions{
+			"slow": {Timeout: time.Minute, Retries: 3, RetryDelay: time.Second},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	results := service.FetchResults(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ProviderService.FetchResults() took %v after cancellation", elapsed)
+	}
+
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("ProviderService.FetchResults() got[%d].Err = %v, want %v", i, result.Err, context.Canceled)
+		}
+		if result.Attempts != 1 {
+			t.Errorf("ProviderService.FetchResults() got[%d].Attempts = %v, want no retries after cancellation", i, result.Attempts)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"todo-planning/internal/model"
+	"todo-planning/internal/provider"
+
+	"gorm.io/gorm"
+)
+
+// PushService writes the assignments of a plan back to the sources of the
+// tasks through the providers that implement provider.AssignmentSink
+type PushService struct {
+	db                *gorm.DB
+	providerService   *ProviderService
+	assignmentService *AssignmentService
+}
+
+func NewPushService(db *gorm.DB, providerService *ProviderService, assignmentService *AssignmentService) *PushService {
+	return &PushService{
+		db:                db,
+		providerService:   providerService,
+		assignmentService: assignmentService,
+	}
+}
+
+// plannedTask is a top-level task of a plan run with the developer of its
+// first part and the week its last part is done in
+type plannedTask struct {
+	task      model.Task
+	developer string
+	start     int
+	sequence  int
+	week      int
+}
+
+// PushPlanRun pushes the developer and target week of every task of a plan
+// run to its source and records the result of each one. A task split into
+// sub-tasks is pushed once with the developer of its first part and the week
+// of its last part. In a dry run nothing is written to the sources.
+func (s *PushService) PushPlanRun(ctx context.Context, planRunID uint, dryRun bool) ([]model.AssignmentPush, error) {
+	run, err := s.assignmentService.GetPlanRun(planRunID)
+	if err != nil {
+		return nil, err
+	}
+
+	planned, err := s.plannedTasks(run.Assignments)
+	if err != nil {
+		return nil, err
+	}
+
+	pushes := make([]model.AssignmentPush, 0, len(planned))
+	for _, p := range planned {
+		push := model.AssignmentPush{
+			PlanRunID:  run.ID,
+			TaskID:     p.task.ID,
+			Source:     p.task.Source,
+			ExternalID: p.task.ExternalID,
+			Developer:  p.developer,
+			WeekNumber: p.week,
+			DryRun:     dryRun,
+		}
+
+		sink, reason := s.sink(p.task.Source)
+		if sink == nil {
+			push.Status = model.PushSkipped
+			push.Message = reason
+			pushes = append(pushes, push)
+			continue
+		}
+
+		message, err := sink.PushAssignment(ctx, provider.PlannedAssignment{
+			ExternalID: p.task.ExternalID,
+			Developer:  p.developer,
+			Week:       p.week,
+			WeekStart:  planWeekStart(run.CreatedAt, p.week),
+		}, dryRun)
+		switch {
+		case err != nil:
+			push.Status = model.PushFailed
+			push.Message = err.Error()
+		case dryRun:
+			push.Status = model.PushDryRun
+			push.Message = message
+		default:
+			push.Status = model.PushSucceeded
+			push.Message = message
+		}
+
+		pushes = append(pushes, push)
+	}
+
+	if len(pushes) > 0 {
+		if err := s.db.CreateInBatches(&pushes, 100).Error; err != nil {
+			return nil, fmt.Errorf("failed to record assignment pushes: %w", err)
+		}
+	}
+
+	return pushes, nil
+}
+
+// planWeekStart returns the Monday of a plan week. Week 1 is the calendar
+// week the plan was created in.
+func planWeekStart(created time.Time, week int) time.Time {
+	day := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)
+	sinceMonday := (int(day.Weekday()) + 6) % 7
+
+	return day.AddDate(0, 0, 7*(week-1)-sinceMonday)
+}
+
+// GetPushes returns the recorded pushes of a plan run in the order they were made
+func (s *PushService) GetPushes(planRunID uint) ([]model.AssignmentPush, error) {
+	if err := s.db.Select("id").First(&model.PlanRun{}, planRunID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get plan run %d: %w", planRunID, err)
+	}
+
+	var pushes []model.AssignmentPush
+	if err := s.db.Where("plan_run_id = ?", planRunID).Order("id").Find(&pushes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get assignment pushes: %w", err)
+	}
+
+	return pushes, nil
+}
+
+// sink returns the provider that takes the assignments of a source, or why
+// there is none
+func (s *PushService) sink(source string) (provider.AssignmentSink, string) {
+	if source == model.SourceManual {
+		return nil, "manual tasks have no source to push to"
+	}
+
+	p := s.providerService.Provider(source)
+	if p == nil {
+		return nil, fmt.Sprintf("provider %s isn't configured", source)
+	}
+
+	sink, ok := p.(provider.AssignmentSink)
+	if !ok {
+		return nil, fmt.Sprintf("provider %s can't take assignments", source)
+	}
+
+	return sink, ""
+}
+
+// plannedTasks folds the assignments of sub-tasks into their parents and
+// orders the tasks by target week
+func (s *PushService) plannedTasks(assignments []model.Assignment) ([]plannedTask, error) {
+	var parentIDs []uint
+	for _, assignment := range assignments {
+		if assignment.Task.ParentID != nil {
+			parentIDs = append(parentIDs, *assignment.Task.ParentID)
+		}
+	}
+
+	parents := make(map[uint]model.Task)
+	if len(parentIDs) > 0 {
+		var stored []model.Task
+		if err := s.db.Unscoped().Where("id IN ?", parentIDs).Find(&stored).Error; err != nil {
+			return nil, fmt.Errorf("failed to get split tasks: %w", err)
+		}
+
+		for _, task := range stored {
+			parents[task.ID] = task
+		}
+	}
+
+	byTask := make(map[uint]*plannedTask)
+	var planned []*plannedTask
+	for _, assignment := range assignments {
+		task := assignment.Task
+		if task.ParentID != nil {
+			parent, ok := parents[*task.ParentID]
+			if !ok {
+				continue
+			}
+			task = parent
+		}
+
+		p, ok := byTask[task.ID]
+		if !ok {
+			p = &plannedTask{task: task, start: assignment.WeekNumber, sequence: assignment.Task.Sequence}
+			p.developer = assignment.Developer.Name
+			byTask[task.ID] = p
+			planned = append(planned, p)
+		}
+
+		if assignment.WeekNumber < p.start || (assignment.WeekNumber == p.start && assignment.Task.Sequence < p.sequence) {
+			p.start = assignment.WeekNumber
+			p.sequence = assignment.Task.Sequence
+			p.developer = assignment.Developer.Name
+		}
+		p.week = max(p.week, assignment.WeekNumber)
+	}
+
+	sort.Slice(planned, func(i, j int) bool {
+		if planned[i].week != planned[j].week {
+			return planned[i].week < planned[j].week
+		}
+
+		return planned[i].task.ID < planned[j].task.ID
+	})
+
+	result := make([]plannedTask, len(planned))
+	for i, p := range planned {
+		result[i] = *p
+	}
+
+	return result, nil
+}
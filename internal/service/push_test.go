@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"todo-planning/internal/model"
+	"todo-planning/internal/provider"
+	"todo-planning/internal/utility"
+
+	"gorm.io/gorm"
+)
+
+// sinkProvider records the assignments pushed to it
+type sinkProvider struct {
+	name   string
+	pushed []provider.PlannedAssignment
+	fail   map[string]bool // external ids the source refuses
+}
+
+func (s *sinkProvider) Name() string {
+	return s.name
+}
+
+func (s *sinkProvider) FetchTasks() ([]model.Task, error) {
+	return nil, nil
+}
+
+func (s *sinkProvider) PushAssignment(ctx context.Context, assignment provider.PlannedAssignment, dryRun bool) (string, error) {
+	if s.fail[assignment.ExternalID] {
+		return "", errors.New("issue not found")
+	}
+
+	if dryRun {
+		return fmt.Sprintf("would assign %s to %s in week %d", assignment.ExternalID, assignment.Developer, assignment.Week), nil
+	}
+
+	s.pushed = append(s.pushed, assignment)
+
+	return fmt.Sprintf("assigned %s to %s in week %d", assignment.ExternalID, assignment.Developer, assignment.Week), nil
+}
+
+func setupPushTest(t *testing.T, providers ...provider.Provider) (*PushService, *gorm.DB, func()) {
+	db := utility.GetTestDB()
+	utility.AutoMigrate(&model.Task{}, &model.Developer{}, &model.Assignment{}, &model.PlanRun{}, &model.AssignmentPush{})
+
+	service := NewPushService(db, &ProviderService{providers: providers}, NewAssignmentService(db))
+
+	// Return cleanup function
+	cleanup := func() {
+		utility.ClearTables()
+		utility.CloseTestDB()
+	}
+
+	return service, db, cleanup
+}
+
+func TestPushService_PushPlanRun(t *testing.T) {
+	sink := &sinkProvider{name: "tracker", fail: map[string]bool{"T-3": true}}
+	service, db, cleanup := setupPushTest(t, sink, &mockProvider{})
+	defer cleanup()
+
+	developers := []model.Developer{{Name: "Dev1", Productivity: 1}, {Name: "Dev2", Productivity: 2}}
+	if err := db.Create(&developers).Error; err != nil {
+		t.Fatalf("Failed to create developers: %v", err)
+	}
+
+	tasks := []model.Task{
+		{ExternalID: "T-1", Source: "tracker", EstimatedDuration: 60},
+		{ExternalID: "T-2", Source: "tracker", EstimatedDuration: 10},
+		{ExternalID: "T-3", Source: "tracker", EstimatedDuration: 10},
+		{ExternalID: "1", Source: "provider-2", EstimatedDuration: 10},
+		{ExternalID: "M-1", Source: model.SourceManual, EstimatedDuration: 10},
+	}
+	if err := db.Create(&tasks).Error; err != nil {
+		t.Fatalf("Failed to create tasks: %v", err)
+	}
+
+	// T-1 is split into two parts, done by Dev2 and Dev1 in weeks 1 and 2
+	parts := []model.Task{
+		{ExternalID: "T-1#1", Source: "tracker", ParentID: &tasks[0].ID, Sequence: 1, EstimatedDuration: 30},
+		{ExternalID: "T-1#2", Source: "tracker", ParentID: &tasks[0].ID, Sequence: 2, EstimatedDuration: 30},
+	}
+	if err := db.Create(&parts).Error; err != nil {
+		t.Fatalf("Failed to create sub-tasks: %v", err)
+	}
+
+	run := &model.PlanRun{Assignments: []model.Assignment{
+		{TaskID: parts[1].ID, DeveloperID: developers[0].ID, WeekNumber: 2},
+		{TaskID: parts[0].ID, DeveloperID: developers[1].ID, WeekNumber: 1},
+		{TaskID: tasks[1].ID, DeveloperID: developers[0].ID, WeekNumber: 1},
+		{TaskID: tasks[2].ID, DeveloperID: developers[1].ID, WeekNumber: 1},
+		{TaskID: tasks[3].ID, DeveloperID: developers[1].ID, WeekNumber: 3},
+		{TaskID: tasks[4].ID, DeveloperID: developers[0].ID, WeekNumber: 3},
+	}}
+	if err := NewAssignmentService(db).CreatePlanRun(run); err != nil {
+		t.Fatalf("Failed to create plan run: %v", err)
+	}
+
+	expected := []struct {
+		externalID string
+		developer  string
+		week       int
+		status     string
+	}{
+		{"T-2", "Dev1", 1, model.PushSucceeded},
+		{"T-3", "Dev2", 1, model.PushFailed},
+		{"T-1", "Dev2", 2, model.PushSucceeded},
+		{"1", "Dev2", 3, model.PushSkipped},
+		{"M-1", "Dev1", 3, model.PushSkipped},
+	}
+
+	// a dry run leaves the source alone
+	pushes, err := service.PushPlanRun(context.Background(), run.ID, true)
+	if err != nil {
+		t.Fatalf("PushService.PushPlanRun() error = %v", err)
+	}
+	if len(sink.pushed) != 0 {
+		t.Errorf("Expected nothing pushed in a dry run, got %v", sink.pushed)
+	}
+	if len(pushes) != len(expected) || pushes[0].Status != model.PushDryRun || !pushes[0].DryRun || pushes[0].Message != "would assign T-2 to Dev1 in week 1" {
+		t.Errorf("PushService.PushPlanRun() got = %+v, want a dry run of %d tasks", pushes, len(expected))
+	}
+
+	pushes, err = service.PushPlanRun(context.Background(), run.ID, false)
+	if err != nil {
+		t.Fatalf("PushService.PushPlanRun() error = %v", err)
+	}
+	if len(pushes) != len(expected) {
+		t.Fatalf("PushService.PushPlanRun() got %d pushes, want %d", len(pushes), len(expected))
+	}
+	for i, push := range pushes {
+		want := expected[i]
+		if push.ExternalID != want.externalID || push.Developer != want.developer || push.WeekNumber != want.week || push.Status != want.status {
+			t.Errorf("PushService.PushPlanRun()[%d] got = %+v, want %s to %s in week %d with status %s",
+				i, push, want.externalID, want.developer, want.week, want.status)
+		}
+		if push.Message == "" {
+			t.Errorf("PushService.PushPlanRun()[%d] has no message", i)
+		}
+	}
+	if len(sink.pushed) != 2 {
+		t.Errorf("Expected 2 assignments pushed to the tracker, got %v", sink.pushed)
+	}
+	for _, assignment := range sink.pushed {
+		if want := planWeekStart(run.CreatedAt, assignment.Week); !assignment.WeekStart.Equal(want) {
+			t.Errorf("PushService.PushPlanRun() pushed week %d starting %v, want %v", assignment.Week, assignment.WeekStart, want)
+		}
+	}
+
+	// both runs are in the log
+	log, err := service.GetPushes(run.ID)
+	if err != nil {
+		t.Fatalf("PushService.GetPushes() error = %v", err)
+	}
+	if len(log) != 2*len(expected) || !log[0].DryRun || log[len(log)-1].DryRun {
+		t.Errorf("PushService.GetPushes() got %d pushes, want the dry run followed by the push", len(log))
+	}
+
+	if _, err := service.PushPlanRun(context.Background(), run.ID+1, false); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("PushService.PushPlanRun() error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+	if _, err := service.GetPushes(run.ID + 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("PushService.GetPushes() error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
+
+func TestPlanWeekStart(t *testing.T) {
+	tests := []struct {
+		created time.Time
+		week    int
+		want    time.Time
+	}{
+		{time.Date(2024, time.June, 5, 15, 30, 0, 0, time.UTC), 1, time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC), 1, time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, time.June, 9, 23, 0, 0, 0, time.UTC), 2, time.Date(2024, time.June, 10, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, time.December, 20, 9, 0, 0, 0, time.UTC), 3, time.Date(2024, time.December, 30, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := planWeekStart(tt.created, tt.week); !got.Equal(tt.want) {
+			t.Errorf("planWeekStart(%v, %d) got = %v, want %v", tt.created, tt.week, got, tt.want)
+		}
+	}
+}
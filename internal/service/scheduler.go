@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"todo-planning/internal/logger"
+	"todo-planning/internal/model"
+	"todo-planning/internal/schedule"
+	"todo-planning/internal/utility"
+)
+
+// TriggerSchedule marks sync jobs started by the SyncScheduler
+const TriggerSchedule = "schedule"
+
+// SchedulerStatus describes the scheduled sync and its last run
+type SchedulerStatus struct {
+	Enabled  bool           `json:"enabled"`
+	Schedule string         `json:"schedule,omitempty"`
+	Jitter   string         `json:"jitter,omitempty"`
+	NextRun  *time.Time     `json:"next_run,omitempty"`
+	LastRun  *time.Time     `json:"last_run,omitempty"`
+	LastJob  *model.SyncJob `json:"last_job,omitempty"`
+	Skipped  int            `json:"skipped"` // runs skipped because a sync was still running
+	Error    string         `json:"error,omitempty"`
+}
+
+// SyncScheduler starts sync jobs on a schedule. A run is skipped while
+// another sync job is still running.
+type SyncScheduler struct {
+	syncService *SyncService
+	schedule    schedule.Schedule
+	spec        string
+	jitter      time.Duration
+
+	mu        sync.Mutex
+	nextRun   *time.Time
+	lastRun   *time.Time
+	lastJobID uint
+	skipped   int
+	lastError string
+}
+
+func NewSyncScheduler(syncService *SyncService, spec string, jitter time.Duration) (*SyncScheduler, error) {
+	parsed, err := schedule.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sync schedule: %w", err)
+	}
+
+	if jitter < 0 {
+		return nil, fmt.Errorf("sync jitter must not be negative, got %s", jitter)
+	}
+
+	return &SyncScheduler{
+		syncService: syncService,
+		schedule:    parsed,
+		spec:        spec,
+		jitter:      jitter,
+	}, nil
+}
+
+// Run starts sync jobs until the context is cancelled
+func (s *SyncScheduler) Run(ctx context.Context) {
+	logger.Info("scheduled sync enabled with schedule ", s.spec)
+
+	for {
+		next := s.schedule.Next(time.Now())
+		if next.IsZero() {
+			logger.Error(fmt.Errorf("sync schedule %s has no next run", s.spec))
+			return
+		}
+
+		if s.jitter > 0 {
+			next = next.Add(rand.N(s.jitter))
+		}
+
+		s.mu.Lock()
+		s.nextRun = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.trigger()
+		}
+	}
+}
+
+func (s *SyncScheduler) trigger() {
+	job, err := s.syncService.Start(TriggerSchedule)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastRun = utility.ToPointer(time.Now())
+	s.lastError = ""
+
+	switch {
+	case errors.Is(err, ErrSyncInProgress):
+		s.skipped++
+		logger.Info("scheduled sync skipped, sync job ", job.ID, " is still running")
+	case err != nil:
+		s.lastError = err.Error()
+		logger.Error(fmt.Errorf("failed to start scheduled sync: %w", err))
+	default:
+		s.lastJobID = job.ID
+	}
+}
+
+// Status returns the schedule together with the last scheduled job
+func (s *SyncScheduler) Status() SchedulerStatus {
+	s.mu.Lock()
+	status := SchedulerStatus{
+		Enabled:  true,
+		Schedule: s.spec,
+		NextRun:  s.nextRun,
+		LastRun:  s.lastRun,
+		Skipped:  s.skipped,
+		Error:    s.lastError,
+	}
+	if s.jitter > 0 {
+		status.Jitter = s.jitter.String()
+	}
+	lastJobID := s.lastJobID
+	s.mu.Unlock()
+
+	if lastJobID != 0 {
+		job, err := s.syncService.GetSyncJob(lastJobID)
+		if err != nil {
+			status.Error = err.Error()
+		} else {
+			status.LastJob = job
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"todo-planning/internal/model"
+)
+
+func TestNewSyncScheduler(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		jitter  time.Duration
+		wantErr bool
+	}{
+		{name: "duration", spec: "15m", jitter: time.Minute},
+		{name: "cron expression", spec: "*/10 * * * *"},
+		{name: "invalid schedule", spec: "every now and then", wantErr: true},
+		{name: "negative jitter", spec: "15m", jitter: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSyncScheduler(&SyncService{}, tt.spec, tt.jitter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSyncScheduler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSyncScheduler_Run(t *testing.T) {
+	blocking := &blockingProvider{release: make(chan struct{})}
+	service, cleanup := setupSyncTest(t, blocking)
+	defer cleanup()
+
+	scheduler, err := NewSyncScheduler(service, "20ms", 0)
+	if err != nil {
+		t.Fatalf("NewSyncScheduler() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	// the first job blocks, so the following runs have to be skipped
+	deadline := time.Now().Add(5 * time.Second)
+	for scheduler.Status().Skipped < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("SyncScheduler.Status() got = %+v, want skipped runs", scheduler.Status())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+	close(blocking.release)
+	service.Wait()
+
+	status := scheduler.Status()
+	if !status.Enabled || status.Schedule != "20ms" || status.LastRun == nil || status.NextRun == nil {
+		t.Errorf("SyncScheduler.Status() got = %+v, want enabled schedule with last and next run", status)
+	}
+
+	if status.LastJob == nil || status.LastJob.Trigger != TriggerSchedule || status.LastJob.Status != model.SyncSucceeded {
+		t.Errorf("SyncScheduler.Status() got.LastJob = %+v, want a succeeded scheduled job", status.LastJob)
+	}
+
+	jobs, err := service.GetSyncJobs(10)
+	if err != nil {
+		t.Fatalf("SyncService.GetSyncJobs() error = %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Errorf("SyncService.GetSyncJobs() got = %v jobs, want 1 job", len(jobs))
+	}
+}
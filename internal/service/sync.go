@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"todo-planning/internal/logger"
+	"todo-planning/internal/model"
+	"todo-planning/internal/provider"
+	"todo-planning/internal/utility"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrSyncInProgress = errors.New("a sync job is already running")
+
+// Sync job triggers
+const (
+	TriggerAPI = "api"
+	TriggerCLI = "cli"
+)
+
+// DefaultRemovalGracePeriod is how long a task may be missing from its
+// provider before it is deleted
+const DefaultRemovalGracePeriod = 24 * time.Hour
+
+// SyncService fetches tasks from the providers and stores them as tracked
+// background jobs. Only one job runs at a time.
+type SyncService struct {
+	db                 *gorm.DB
+	providerService    *ProviderService
+	taskService        *TaskService
+	removalGracePeriod time.Duration
+
+	mu        sync.Mutex
+	runningID uint               // the job running in the background, 0 when idle
+	cancel    context.CancelFunc // cancels the running job
+	wg        sync.WaitGroup
+}
+
+func NewSyncService(db *gorm.DB, providerService *ProviderService, taskService *TaskService) *SyncService {
+	return &SyncService{
+		db:                 db,
+		providerService:    providerService,
+		taskService:        taskService,
+		removalGracePeriod: DefaultRemovalGracePeriod,
+	}
+}
+
+// SetRemovalGracePeriod sets how long a task may be missing from its provider
+// before it is deleted, zero deletes it with the first sync that misses it
+func (s *SyncService) SetRemovalGracePeriod(gracePeriod time.Duration) {
+	s.removalGracePeriod = gracePeriod
+}
+
+// Run creates a sync job and runs it in the foreground
+func (s *SyncService) Run(trigger string) (*model.SyncJob, error) {
+	job, err := s.Start(trigger)
+	if err != nil {
+		return job, err
+	}
+
+	s.Wait()
+
+	return s.GetSyncJob(job.ID)
+}
+
+// Start creates a sync job and runs it in the background. It returns
+// ErrSyncInProgress together with the running job when one is still going.
+func (s *SyncService) Start(trigger string) (*model.SyncJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.runningID != 0 {
+		job, err := s.GetSyncJob(s.runningID)
+		if err != nil {
+			return nil, err
+		}
+
+		return job, ErrSyncInProgress
+	}
+
+	job := &model.SyncJob{
+		Status:    model.SyncPending,
+		Trigger:   trigger,
+		Providers: []model.ProviderSyncResult{},
+	}
+	if err := s.db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create sync job: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.runningID = job.ID
+	s.cancel = cancel
+	created := *job
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+
+		s.run(ctx, job)
+
+		s.mu.Lock()
+		s.runningID = 0
+		s.cancel = nil
+		s.mu.Unlock()
+	}()
+
+	return &created, nil
+}
+
+// Cancel stops the fetches of the running job, if any. The tasks fetched so
+// far are still stored.
+func (s *SyncService) Cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// Wait blocks until the background job, if any, has finished
+func (s *SyncService) Wait() {
+	s.wg.Wait()
+}
+
+// GetSyncJob returns a sync job
+func (s *SyncService) GetSyncJob(id uint) (*model.SyncJob, error) {
+	var job model.SyncJob
+	if err := s.db.First(&job, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sync job %d: %w", id, err)
+	}
+
+	return &job, nil
+}
+
+// GetSyncJobs returns the most recent sync jobs, newest first
+func (s *SyncService) GetSyncJobs(limit int) ([]model.SyncJob, error) {
+	var jobs []model.SyncJob
+	if err := s.db.Order("id DESC").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sync jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// run fetches and stores the tasks of every provider and records the outcome
+func (s *SyncService) run(ctx context.Context, job *model.SyncJob) {
+	job.Status = model.SyncRunning
+	job.StartedAt = utility.ToPointer(time.Now())
+	s.save(job)
+
+	cursors, err := s.getCursors()
+	if err != nil {
+		// without cursors every provider fetches all of its tasks
+		logger.Error(err)
+	}
+
+	// pages are stored while the providers are still fetching, one at a time.
+	// Every attempt of a provider starts counting anew, a retry stores its
+	// pages again.
+	var mu sync.Mutex
+	pages := make(map[int]*storedPages)
+	start := func(index int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		pages[index] = &storedPages{externalIDs: make(map[string][]string)}
+	}
+	results := s.providerService.StreamResults(ctx, cursors, start, func(index int, tasks []model.Task) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return pages[index].store(s.taskService, tasks)
+	})
+
+	failed := 0
+	for i, result := range results {
+		providerResult := model.ProviderSyncResult{
+			Provider:    result.Provider,
+			NotModified: result.NotModified,
+			Incremental: result.Partial,
+			LatencyMs:   result.Latency.Milliseconds(),
+			Attempts:    result.Attempts,
+			Fetched:     result.Fetched,
+		}
+
+		stored := pages[i]
+		if stored == nil {
+			stored = &storedPages{externalIDs: make(map[string][]string)}
+		}
+		providerResult.Stored = stored.stored
+		providerResult.Inserted = stored.summary.Inserted
+		providerResult.Updated = stored.summary.Updated
+		providerResult.Unchanged = stored.summary.Unchanged
+		providerResult.Restored = stored.summary.Restored
+
+		// tasks are only removed when the provider returned all of its tasks
+		err := result.Err
+		if err == nil && !result.NotModified && !result.Partial {
+			err = s.reconcile(result.Source, stored, &providerResult)
+		}
+
+		if err == nil && result.Cursor != nil {
+			err = s.saveCursor(result.Provider, result.Cursor)
+		}
+
+		if err != nil {
+			providerResult.Error = err.Error()
+			failed++
+		} else {
+			providerResult.Success = true
+		}
+
+		job.Fetched += providerResult.Fetched
+		job.Stored += providerResult.Stored
+		job.Inserted += providerResult.Inserted
+		job.Updated += providerResult.Updated
+		job.Unchanged += providerResult.Unchanged
+		job.Restored += providerResult.Restored
+		job.Removed += len(providerResult.Removed)
+		job.Providers = append(job.Providers, providerResult)
+	}
+
+	switch {
+	case failed == 0:
+		job.Status = model.SyncSucceeded
+	case failed < len(job.Providers):
+		job.Status = model.SyncPartial
+		job.Error = fmt.Sprintf("%d of %d providers failed", failed, len(job.Providers))
+	default:
+		job.Status = model.SyncFailed
+		job.Error = "all providers failed"
+	}
+
+	job.FinishedAt = utility.ToPointer(time.Now())
+	s.save(job)
+
+	logger.Info("sync job ", job.ID, " finished with status ", job.Status, ", fetched ", job.Fetched, " tasks: ",
+		job.Inserted, " inserted, ", job.Updated, " updated, ", job.Unchanged, " unchanged, ", job.Removed, " removed")
+}
+
+// storedPages keeps track of the pages of a provider stored during a sync
+type storedPages struct {
+	stored      int
+	summary     StoreSummary
+	externalIDs map[string][]string // by source
+}
+
+// store upserts a page of tasks
+func (p *storedPages) store(taskService *TaskService, tasks []model.Task) error {
+	summary, err := taskService.UpsertTasks(tasks)
+	if err != nil {
+		return err
+	}
+
+	p.stored += len(tasks)
+	p.summary.Inserted += summary.Inserted
+	p.summary.Updated += summary.Updated
+	p.summary.Unchanged += summary.Unchanged
+	p.summary.Restored += summary.Restored
+
+	for _, task := range tasks {
+		p.externalIDs[task.Source] = append(p.externalIDs[task.Source], task.ExternalID)
+	}
+
+	return nil
+}
+
+// reconcile removes the tasks a provider no longer returns from every source
+// it reported
+func (s *SyncService) reconcile(source string, stored *storedPages, providerResult *model.ProviderSyncResult) error {
+	if _, ok := stored.externalIDs[source]; source != "" && !ok {
+		stored.externalIDs[source] = nil
+	}
+
+	for source, ids := range stored.externalIDs {
+		reconciled, err := s.taskService.ReconcileTasks(source, ids, s.removalGracePeriod)
+		if err != nil {
+			return err
+		}
+
+		providerResult.Missing += reconciled.Missing
+		providerResult.Removed = append(providerResult.Removed, reconciled.Removed...)
+	}
+
+	return nil
+}
+
+// getCursors returns where the incremental fetches continue from, by source
+func (s *SyncService) getCursors() (map[string]provider.Cursor, error) {
+	var stored []model.SyncCursor
+	if err := s.db.Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sync cursors: %w", err)
+	}
+
+	cursors := make(map[string]provider.Cursor, len(stored))
+	for _, cursor := range stored {
+		cursors[cursor.Source] = provider.Cursor{
+			ETag:         cursor.ETag,
+			LastModified: cursor.LastModified,
+			UpdatedSince: cursor.UpdatedSince,
+			FullSyncAt:   cursor.FullSyncAt,
+		}
+	}
+
+	return cursors, nil
+}
+
+// saveCursor stores where the next incremental fetch of a source continues from
+func (s *SyncService) saveCursor(source string, cursor *provider.Cursor) error {
+	stored := model.SyncCursor{
+		Source:       source,
+		ETag:         cursor.ETag,
+		LastModified: cursor.LastModified,
+		UpdatedSince: cursor.UpdatedSince,
+		FullSyncAt:   cursor.FullSyncAt,
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}},
+		DoUpdates: clause.AssignmentColumns([]string{"etag", "last_modified", "updated_since", "full_sync_at", "updated_at"}),
+	}).Create(&stored).Error
+	if err != nil {
+		return fmt.Errorf("failed to save sync cursor of %s: %w", source, err)
+	}
+
+	return nil
+}
+
+func (s *SyncService) save(job *model.SyncJob) {
+	if err := s.db.Save(job).Error; err != nil {
+		logger.Error(fmt.Errorf("failed to save sync job %d: %w", job.ID, err))
+	}
+}
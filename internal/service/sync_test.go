@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"todo-planning/internal/model"
+	"todo-planning/internal/provider"
+	"todo-planning/internal/utility"
+)
+
+func setupSyncTest(t *testing.T, providers ...provider.Provider) (*SyncService, func()) {
+	db := utility.GetTestDB()
+	utility.AutoMigrate(&model.Task{}, &model.TaskRevision{}, &model.SyncJob{}, &model.SyncCursor{})
+
+	service := NewSyncService(db, &ProviderService{providers: providers}, NewTaskService(db))
+
+	// Return cleanup function
+	cleanup := func() {
+		utility.ClearTables()
+		utility.CloseTestDB()
+	}
+
+	return service, cleanup
+}
+
+func TestSyncService_Start(t *testing.T) {
+	tests := []struct {
+		name        string
+		providers   []provider.Provider
+		wantStatus  string
+		wantFetched int
+		wantErrors  []bool
+	}{
+		{
+			name: "all providers succeed",
+			providers: []provider.Provider{
+				&mockProvider{tasks: []model.Task{
+					{ExternalID: "1", Source: "mock-one", Name: utility.ToPointer("Task 1")},
+					{ExternalID: "2", Source: "mock-one", Name: utility.ToPointer("Task 2")},
+				}},
+				&mockProvider{tasks: []model.Task{
+					{ExternalID: "1", Source: "mock-two", Name: utility.ToPointer("Task 1")},
+				}},
+			},
+			wantStatus:  model.SyncSucceeded,
+			wantFetched: 3,
+			wantErrors:  []bool{false, false},
+		},
+		{
+			name: "one provider fails",
+			providers: []provider.Provider{
+				&mockProvider{err: errors.New("provider error")},
+				&mockProvider{tasks: []model.Task{
+					{ExternalID: "1", Source: "mock-two", Name: utility.ToPointer("Task 1")},
+				}},
+			},
+			wantStatus:  model.SyncPartial,
+			wantFetched: 1,
+			wantErrors:  []bool{true, false},
+		},
+		{
+			name: "every provider fails",
+			providers: []provider.Provider{
+				&mockProvider{err: errors.New("provider error")},
+			},
+			wantStatus:  model.SyncFailed,
+			wantFetched: 0,
+			wantErrors:  []bool{true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, cleanup := setupSyncTest(t, tt.providers...)
+			defer cleanup()
+
+			job, err := service.Start(TriggerAPI)
+			if err != nil {
+				t.Fatalf("SyncService.Start() error = %v", err)
+			}
+			if job.Status != model.SyncPending {
+				t.Errorf("SyncService.Start() got.Status = %v, want %v", job.Status, model.SyncPending)
+			}
+
+			service.Wait()
+
+			got, err := service.GetSyncJob(job.ID)
+			if err != nil {
+				t.Fatalf("SyncService.GetSyncJob() error = %v", err)
+			}
+
+			if got.Status != tt.wantStatus {
+				t.Errorf("SyncService.GetSyncJob() got.Status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if got.Fetched != tt.wantFetched || got.Stored != tt.wantFetched || got.Inserted != tt.wantFetched {
+				t.Errorf("SyncService.GetSyncJob() got fetched %v stored %v inserted %v, want %v", got.Fetched, got.Stored, got.Inserted, tt.wantFetched)
+			}
+			if got.StartedAt == nil || got.FinishedAt == nil {
+				t.Errorf("SyncService.GetSyncJob() got = %+v, want start and finish times", got)
+			}
+
+			if len(got.Providers) != len(tt.wantErrors) {
+				t.Fatalf("SyncService.GetSyncJob() got %v provider results, want %v", len(got.Providers), len(tt.wantErrors))
+			}
+			for i, result := range got.Providers {
+				if (result.Error != "") != tt.wantErrors[i] || result.Success == tt.wantErrors[i] {
+					t.Errorf("SyncService.GetSyncJob() provider %s error = %q, want error %v", result.Provider, result.Error, tt.wantErrors[i])
+				}
+				if result.Attempts != 1 {
+					t.Errorf("SyncService.GetSyncJob() provider %s attempts = %v, want 1", result.Provider, result.Attempts)
+				}
+			}
+
+			tasks, err := service.taskService.GetTasks()
+			if err != nil {
+				t.Fatalf("TaskService.GetTasks() error = %v", err)
+			}
+			if len(tasks) != tt.wantFetched {
+				t.Errorf("TaskService.GetTasks() got = %v tasks, want %v tasks", len(tasks), tt.wantFetched)
+			}
+		})
+	}
+}
+
+type blockingProvider struct {
+	release chan struct{}
+}
+
+func (b *blockingProvider) FetchTasks() ([]model.Task, error) {
+	<-b.release
+	return nil, nil
+}
+
+func TestSyncService_StartOverlap(t *testing.T) {
+	blocking := &blockingProvider{release: make(chan struct{})}
+	service, cleanup := setupSyncTest(t, blocking)
+	defer cleanup()
+
+	first, err := service.Start(TriggerAPI)
+	if err != nil {
+		t.Fatalf("SyncService.Start() error = %v", err)
+	}
+
+	running, err := service.Start(TriggerAPI)
+	if !errors.Is(err, ErrSyncInProgress) {
+		t.Errorf("SyncService.Start() error = %v, want %v", err, ErrSyncInProgress)
+	}
+	if running == nil || running.ID != first.ID {
+		t.Errorf("SyncService.Start() got = %+v, want running job %d", running, first.ID)
+	}
+
+	close(blocking.release)
+	service.Wait()
+
+	if _, err := service.Start(TriggerAPI); err != nil {
+		t.Errorf("SyncService.Start() error = %v after the running job finished", err)
+	}
+	service.Wait()
+}
+
+func TestSyncService_Cancel(t *testing.T) {
+	blocking := &blockingProvider{release: make(chan struct{})}
+	defer close(blocking.release)
+
+	service, cleanup := setupSyncTest(t, blocking)
+	defer cleanup()
+
+	job, err := service.Start(TriggerAPI)
+	if err != nil {
+		t.Fatalf("SyncService.Start() error = %v", err)
+	}
+
+	service.Cancel()
+	service.Wait()
+
+	got, err := service.GetSyncJob(job.ID)
+	if err != nil {
+		t.Fatalf("SyncService.GetSyncJob() error = %v", err)
+	}
+	if got.Status != model.SyncFailed || len(got.Providers) != 1 || got.Providers[0].Success {
+		t.Errorf("SyncService.GetSyncJob() got = %+v, want a failed job", got)
+	}
+}
+
+type namedProvider struct {
+	mockProvider
+	name string
+}
+
+func (n *namedProvider) Name() string {
+	return n.name
+}
+
+func TestSyncService_RunRemovesMissingTasks(t *testing.T) {
+	named := &namedProvider{
+		name: "mock-one",
+		mockProvider: mockProvider{tasks: []model.Task{
+			{ExternalID: "1", Source: "mock-one", Name: utility.ToPointer("Task 1")},
+			{ExternalID: "2", Source: "mock-one", Name: utility.ToPointer("Task 2")},
+		}},
+	}
+	service, cleanup := setupSyncTest(t, named)
+	defer cleanup()
+	service.SetRemovalGracePeriod(0)
+
+	if _, err := service.Run(TriggerAPI); err != nil {
+		t.Fatalf("SyncService.Run() error = %v", err)
+	}
+
+	// the provider stops returning every task
+	named.tasks = nil
+
+	job, err := service.Run(TriggerAPI)
+	if err != nil {
+		t.Fatalf("SyncService.Run() error = %v", err)
+	}
+
+	if job.Status != model.SyncSucceeded || job.Removed != 2 {
+		t.Fatalf("SyncService.Run() got = %+v, want 2 removed tasks", job)
+	}
+	if removed := job.Providers[0].Removed; len(removed) != 2 || removed[0].Source != "mock-one" {
+		t.Errorf("SyncService.Run() got removed = %+v, want both mock-one tasks", removed)
+	}
+
+	// a task that comes back is restored
+	named.tasks = []model.Task{{ExternalID: "1", Source: "mock-one", Name: utility.ToPointer("Task 1")}}
+	if job, err = service.Run(TriggerAPI); err != nil || job.Restored != 1 {
+		t.Fatalf("SyncService.Run() got = %+v, %v, want 1 restored task", job, err)
+	}
+
+	// a failed fetch doesn't remove anything
+	named.err = errors.New("provider error")
+	if job, err = service.Run(TriggerAPI); err != nil || job.Status != model.SyncFailed || job.Removed != 0 {
+		t.Errorf("SyncService.Run() got = %+v, %v, want a failed job without removed tasks", job, err)
+	}
+}
+
+// pagedProvider hands over its tasks page by page, failing after failAfter
+// pages when set. Only the first failures attempts fail when failures is set.
+type pagedProvider struct {
+	name      string
+	pages     [][]model.Task
+	failAfter int
+	failures  int
+	attempts  int
+}
+
+func (p *pagedProvider) Name() string {
+	return p.name
+}
+
+func (p *pagedProvider) FetchTasks() ([]model.Task, error) {
+	var tasks []model.Task
+	for _, page := range p.pages {
+		tasks = append(tasks, page...)
+	}
+
+	return tasks, nil
+}
+
+func (p *pagedProvider) StreamTasks(ctx context.Context, handle func(tasks []model.Task) error) error {
+	p.attempts++
+	failing := p.failAfter > 0 && (p.failures == 0 || p.attempts <= p.failures)
+
+	for i, page := range p.pages {
+		if failing && i == p.failAfter {
+			return errors.New("provider error")
+		}
+
+		if err := handle(page); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestSyncService_RunStoresPages(t *testing.T) {
+	paged := &pagedProvider{
+		name: "paged",
+		pages: [][]model.Task{
+			{
+				{ExternalID: "1", Source: "paged", Name: utility.ToPointer("Task 1")},
+				{ExternalID: "2", Source: "paged", Name: utility.ToPointer("Task 2")},
+			},
+			{
+				{ExternalID: "3", Source: "paged", Name: utility.ToPointer("Task 3")},
+			},
+		},
+	}
+	service, cleanup := setupSyncTest(t, paged)
+	defer cleanup()
+	service.SetRemovalGracePeriod(0)
+
+	job, err := service.Run(TriggerAPI)
+	if err != nil {
+		t.Fatalf("SyncService.Run() error = %v", err)
+	}
+	if job.Status != model.SyncSucceeded || job.Fetched != 3 || job.Inserted != 3 {
+		t.Fatalf("SyncService.Run() got = %+v, want 3 inserted tasks", job)
+	}
+
+	// a fetch failing after the first page stores it but removes nothing
+	paged.pages[0] = paged.pages[0][:1]
+	paged.failAfter = 1
+
+	job, err = service.Run(TriggerAPI)
+	if err != nil {
+		t.Fatalf("SyncService.Run() error = %v", err)
+	}
+	if job.Status != model.SyncFailed || job.Stored != 1 || job.Unchanged != 1 || job.Removed != 0 {
+		t.Errorf("SyncService.Run() got = %+v, want a failed job with 1 stored and no removed task", job)
+	}
+
+	tasks, err := service.taskService.GetTasks()
+	if err != nil {
+		t.Fatalf("TaskService.GetTasks() error = %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Errorf("TaskService.GetTasks() got = %v tasks, want 3 tasks", len(tasks))
+	}
+}
+
+func TestSyncService_RunRetriedPages(t *testing.T) {
+	paged := &pagedProvider{
+		name: "paged",
+		pages: [][]model.Task{
+			{
+				{ExternalID: "1", Source: "paged", Name: utility.ToPointer("Task 1")},
+				{ExternalID: "2", Source: "paged", Name: utility.ToPointer("Task 2")},
+			},
+			{
+				{ExternalID: "3", Source: "paged", Name: utility.ToPointer("Task 3")},
+			},
+		},
+		failAfter: 1,
+		failures:  1,
+	}
+	service, cleanup := setupSyncTest(t, paged)
+	defer cleanup()
+	service.providerService.options = map[string]FetchOptions{"paged": {Timeout: time.Second, Retries: 1}}
+
+	// the retry hands over the first page again, it's only counted once
+	job, err := service.Run(TriggerAPI)
+	if err != nil {
+		t.Fatalf("SyncService.Run() error = %v", err)
+	}
+	if len(job.Providers) != 1 || job.Providers[0].Attempts != 2 {
+		t.Fatalf("SyncService.Run() got = %+v, want a provider fetched in 2 attempts", job.Providers)
+	}
+	if job.Status != model.SyncSucceeded || job.Fetched != 3 || job.Stored != 3 || job.Inserted+job.Unchanged != 3 {
+		t.Errorf("SyncService.Run() got = %+v, want 3 fetched and 3 stored tasks", job)
+	}
+}
+
+// incrementalProvider returns its tasks once and then reports them unchanged,
+// or only the changed tasks when partial is set
+type incrementalProvider struct {
+	namedProvider
+	partial bool
+	cursors []provider.Cursor
+}
+
+func (p *incrementalProvider) StreamChanges(ctx context.Context, cursor provider.Cursor, handle func(tasks []model.Task) error) (provider.Changes, error) {
+	p.cursors = append(p.cursors, cursor)
+
+	if cursor.ETag == "" || p.partial {
+		if err := handle(p.tasks); err != nil {
+			return provider.Changes{}, err
+		}
+
+		return provider.Changes{Cursor: &provider.Cursor{ETag: `"v1"`}, Partial: cursor.ETag != ""}, nil
+	}
+
+	return provider.Changes{Cursor: &cursor, NotModified: true}, nil
+}
+
+func TestSyncService_RunIncremental(t *testing.T) {
+	incremental := &incrementalProvider{namedProvider: namedProvider{
+		name: "mock-one",
+		mockProvider: mockProvider{tasks: []model.Task{
+			{ExternalID: "1", Source: "mock-one", Name: utility.ToPointer("Task 1")},
+			{ExternalID: "2", Source: "mock-one", Name: utility.ToPointer("Task 2")},
+		}},
+	}}
+	service, cleanup := setupSyncTest(t, incremental)
+	defer cleanup()
+	service.SetRemovalGracePeriod(0)
+
+	if job, err := service.Run(TriggerAPI); err != nil || job.Inserted != 2 {
+		t.Fatalf("SyncService.Run() got = %+v, %v, want 2 inserted tasks", job, err)
+	}
+
+	var cursor model.SyncCursor
+	if err := service.db.Where("source = ?", "mock-one").First(&cursor).Error; err != nil || cursor.ETag != `"v1"` {
+		t.Fatalf("Expected a stored cursor with the ETag, got %+v, %v", cursor, err)
+	}
+
+	// nothing changed, nothing is stored or removed
+	job, err := service.Run(TriggerAPI)
+	if err != nil {
+		t.Fatalf("SyncService.Run() error = %v", err)
+	}
+	if incremental.cursors[1].ETag != `"v1"` {
+		t.Errorf("Expected the stored cursor to be sent, got %+v", incremental.cursors[1])
+	}
+	if result := job.Providers[0]; !result.Success || !result.NotModified || result.Stored != 0 || len(result.Removed) != 0 {
+		t.Errorf("SyncService.Run() got = %+v, want a not modified provider", result)
+	}
+
+	// a partial fetch stores the changed tasks without removing the others
+	incremental.partial = true
+	incremental.tasks = incremental.tasks[1:]
+	job, err = service.Run(TriggerAPI)
+	if err != nil {
+		t.Fatalf("SyncService.Run() error = %v", err)
+	}
+	if result := job.Providers[0]; !result.Success || !result.Incremental || result.Stored != 1 || result.Missing != 0 || len(result.Removed) != 0 {
+		t.Errorf("SyncService.Run() got = %+v, want an incremental fetch without removed tasks", result)
+	}
+
+	tasks, err := service.taskService.GetTasks()
+	if err != nil {
+		t.Fatalf("TaskService.GetTasks() error = %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("TaskService.GetTasks() got = %v tasks, want 2 tasks", len(tasks))
+	}
+}
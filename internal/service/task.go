@@ -1,7 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"todo-planning/internal/model"
 
@@ -9,6 +12,59 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+	ErrInvalidTask     = errors.New("invalid task")
+	ErrDuplicateTask   = errors.New("task external id already exists")
+)
+
+// upsertBatchSize bounds the number of tasks read or written per query
+const upsertBatchSize = 500
+
+// StoreSummary counts what happened to the tasks given to UpsertTasks
+type StoreSummary struct {
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Restored  int `json:"restored"` // tasks removed upstream earlier that came back
+}
+
+// ReconcileSummary reports the tasks of a source its provider no longer returns
+type ReconcileSummary struct {
+	Missing int                 // still within the grace period
+	Removed []model.RemovedTask // soft deleted
+}
+
+// DefaultTaskLimit and MaxTaskLimit bound the page size of ListTasks
+const (
+	DefaultTaskLimit = 50
+	MaxTaskLimit     = 500
+)
+
+// TaskFilter selects the tasks returned by ListTasks. Zero values don't filter.
+type TaskFilter struct {
+	Source        string
+	MinDifficulty *float64
+	MaxDifficulty *float64
+	MinDuration   *float64
+	MaxDuration   *float64
+	Limit         int
+	Offset        int
+}
+
+// ChangedByAPI marks task revisions made through the API
+const ChangedByAPI = "api"
+
+// TaskUpdate holds the fields of a task that can be changed, nil fields are kept
+type TaskUpdate struct {
+	Name              *string
+	Difficulty        *float64
+	EstimatedDuration *float64
+	Priority          *int
+	Deadline          *time.Time
+	ClearDeadline     bool // removes the deadline
+}
+
 type TaskService struct {
 	db *gorm.DB
 }
@@ -19,16 +75,330 @@ func NewTaskService(db *gorm.DB) *TaskService {
 	}
 }
 
-// StoreTasks stores tasks in the database using ON CONFLICT DO NOTHING
+// StoreTasks inserts new tasks and updates the ones that changed
 func (s *TaskService) StoreTasks(tasks []model.Task) error {
+	_, err := s.UpsertTasks(tasks)
+
+	return err
+}
+
+// UpsertTasks inserts new tasks and updates the name and estimates of stored
+// tasks that changed, recording a revision for every update. Tasks are
+// matched by source and external id. Tasks removed upstream are restored
+// when they come back, other deleted tasks are left alone.
+func (s *TaskService) UpsertTasks(tasks []model.Task) (StoreSummary, error) {
+	var summary StoreSummary
 	if len(tasks) == 0 {
+		return summary, nil
+	}
+
+	// the last task wins when a provider sends the same task twice
+	incoming := make(map[taskKey]int, len(tasks))
+	unique := make([]model.Task, 0, len(tasks))
+	for _, task := range tasks {
+		key := taskKey{source: task.Source, externalID: task.ExternalID}
+		if i, ok := incoming[key]; ok {
+			unique[i] = task
+			continue
+		}
+
+		incoming[key] = len(unique)
+		unique = append(unique, task)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findTasksByKey(tx, unique)
+		if err != nil {
+			return err
+		}
+
+		var inserts []model.Task
+		for _, task := range unique {
+			stored, ok := existing[taskKey{source: task.Source, externalID: task.ExternalID}]
+			switch {
+			case !ok:
+				inserts = append(inserts, task)
+			case stored.DeletedAt.Valid && !stored.RemovedUpstream:
+				summary.Unchanged++
+			default:
+				if stored.MissingSince != nil || stored.RemovedUpstream {
+					if err := restoreTask(tx, stored.ID); err != nil {
+						return err
+					}
+				}
+
+				// priorities and deadlines are set through the API, providers don't send them
+				task.Priority = stored.Priority
+				task.Deadline = stored.Deadline
+
+				changes := diffTask(stored, task)
+				if len(changes) > 0 {
+					if err := updateTask(tx, stored.ID, task, task.Source, changes); err != nil {
+						return err
+					}
+				}
+
+				switch {
+				case stored.RemovedUpstream:
+					summary.Restored++
+				case len(changes) > 0:
+					summary.Updated++
+				default:
+					summary.Unchanged++
+				}
+			}
+		}
+
+		if len(inserts) > 0 {
+			if err := tx.Omit(clause.Associations).CreateInBatches(&inserts, upsertBatchSize).Error; err != nil {
+				return fmt.Errorf("failed to create tasks: %w", err)
+			}
+			summary.Inserted = len(inserts)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return StoreSummary{}, fmt.Errorf("failed to store tasks: %w", err)
+	}
+
+	return summary, nil
+}
+
+// ReconcileTasks marks the top-level tasks of a source that are not among
+// the external ids returned by a full sync as missing. Tasks missing for
+// longer than the grace period are soft deleted together with their
+// sub-tasks.
+func (s *TaskService) ReconcileTasks(source string, externalIDs []string, gracePeriod time.Duration) (ReconcileSummary, error) {
+	var summary ReconcileSummary
+
+	returned := make(map[string]struct{}, len(externalIDs))
+	for _, externalID := range externalIDs {
+		returned[externalID] = struct{}{}
+	}
+
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var stored []model.Task
+		if err := tx.Where("source = ? AND parent_id IS NULL", source).Find(&stored).Error; err != nil {
+			return fmt.Errorf("failed to get tasks of source %s: %w", source, err)
+		}
+
+		for _, task := range stored {
+			if _, ok := returned[task.ExternalID]; ok {
+				continue
+			}
+
+			if task.MissingSince == nil {
+				task.MissingSince = &now
+				if err := tx.Model(&task).Update("missing_since", now).Error; err != nil {
+					return fmt.Errorf("failed to mark task %d missing: %w", task.ID, err)
+				}
+			}
+
+			if now.Sub(*task.MissingSince) < gracePeriod {
+				summary.Missing++
+				continue
+			}
+
+			removed, err := removeTask(tx, task)
+			if err != nil {
+				return err
+			}
+			summary.Removed = append(summary.Removed, removed)
+		}
+
 		return nil
+	})
+	if err != nil {
+		return ReconcileSummary{}, err
 	}
 
-	return s.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "source"}, {Name: "external_id"}},
-		DoNothing: true,
-	}).Create(&tasks).Error
+	return summary, nil
+}
+
+// RemoveTasks soft deletes the top-level tasks of a source that were deleted
+// upstream, together with their sub-tasks. They are restored when the source
+// returns them again. Unknown external ids are ignored.
+func (s *TaskService) RemoveTasks(source string, externalIDs []string) ([]model.RemovedTask, error) {
+	var removed []model.RemovedTask
+	if len(externalIDs) == 0 {
+		return removed, nil
+	}
+
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var stored []model.Task
+		err := tx.Where("source = ? AND external_id IN ? AND parent_id IS NULL", source, externalIDs).Find(&stored).Error
+		if err != nil {
+			return fmt.Errorf("failed to get tasks of source %s: %w", source, err)
+		}
+
+		for _, task := range stored {
+			if task.MissingSince == nil {
+				task.MissingSince = &now
+			}
+
+			result, err := removeTask(tx, task)
+			if err != nil {
+				return err
+			}
+			removed = append(removed, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+// removeTask marks a task removed upstream and soft deletes it together with
+// its sub-tasks
+func removeTask(tx *gorm.DB, task model.Task) (model.RemovedTask, error) {
+	if err := tx.Model(&task).Update("removed_upstream", true).Error; err != nil {
+		return model.RemovedTask{}, fmt.Errorf("failed to mark task %d removed: %w", task.ID, err)
+	}
+
+	if err := tx.Where("id = ? OR parent_id = ?", task.ID, task.ID).Delete(&model.Task{}).Error; err != nil {
+		return model.RemovedTask{}, fmt.Errorf("failed to delete task %d: %w", task.ID, err)
+	}
+
+	return model.RemovedTask{
+		TaskID:       task.ID,
+		Source:       task.Source,
+		ExternalID:   task.ExternalID,
+		Name:         task.DisplayName(),
+		MissingSince: *task.MissingSince,
+	}, nil
+}
+
+// GetRevisions returns the change history of a task, newest first
+func (s *TaskService) GetRevisions(taskID uint) ([]model.TaskRevision, error) {
+	if err := s.db.Unscoped().Select("id").First(&model.Task{}, taskID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get task %d: %w", taskID, err)
+	}
+
+	var revisions []model.TaskRevision
+	if err := s.db.Where("task_id = ?", taskID).Order("id DESC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get task revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+type taskKey struct {
+	source     string
+	externalID string
+}
+
+// findTasksByKey loads the stored tasks, including deleted ones, with the
+// source and external id of the given tasks
+func findTasksByKey(tx *gorm.DB, tasks []model.Task) (map[taskKey]model.Task, error) {
+	bySource := make(map[string][]string)
+	for _, task := range tasks {
+		bySource[task.Source] = append(bySource[task.Source], task.ExternalID)
+	}
+
+	existing := make(map[taskKey]model.Task, len(tasks))
+	for source, externalIDs := range bySource {
+		for start := 0; start < len(externalIDs); start += upsertBatchSize {
+			end := min(start+upsertBatchSize, len(externalIDs))
+
+			var stored []model.Task
+			err := tx.Unscoped().
+				Where("source = ? AND external_id IN ?", source, externalIDs[start:end]).
+				Find(&stored).Error
+			if err != nil {
+				return nil, fmt.Errorf("failed to get stored tasks: %w", err)
+			}
+
+			for _, task := range stored {
+				existing[taskKey{source: task.Source, externalID: task.ExternalID}] = task
+			}
+		}
+	}
+
+	return existing, nil
+}
+
+// diffTask returns the fields that differ between a stored and a fetched task
+func diffTask(stored, task model.Task) []model.TaskChange {
+	var changes []model.TaskChange
+
+	if !equalNames(stored.Name, task.Name) {
+		changes = append(changes, model.TaskChange{Field: "name", OldValue: stored.Name, NewValue: task.Name})
+	}
+	if stored.Difficulty != task.Difficulty {
+		changes = append(changes, model.TaskChange{Field: "difficulty", OldValue: stored.Difficulty, NewValue: task.Difficulty})
+	}
+	if stored.EstimatedDuration != task.EstimatedDuration {
+		changes = append(changes, model.TaskChange{Field: "estimated_duration", OldValue: stored.EstimatedDuration, NewValue: task.EstimatedDuration})
+	}
+	if stored.Priority != task.Priority {
+		changes = append(changes, model.TaskChange{Field: "priority", OldValue: stored.Priority, NewValue: task.Priority})
+	}
+	if !equalTimes(stored.Deadline, task.Deadline) {
+		changes = append(changes, model.TaskChange{Field: "deadline", OldValue: stored.Deadline, NewValue: task.Deadline})
+	}
+
+	return changes
+}
+
+func equalNames(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+// restoreTask brings back a task removed upstream and clears its missing mark
+func restoreTask(tx *gorm.DB, id uint) error {
+	err := tx.Unscoped().Model(&model.Task{ID: id}).Updates(map[string]any{
+		"missing_since":    nil,
+		"removed_upstream": false,
+		"deleted_at":       nil,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to restore task %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// updateTask writes the name, estimates, priority and deadline of a task and
+// records the revision
+func updateTask(tx *gorm.DB, id uint, task model.Task, changedBy string, changes []model.TaskChange) error {
+	err := tx.Model(&model.Task{ID: id}).
+		Select("name", "difficulty", "estimated_duration", "priority", "deadline").
+		Updates(&model.Task{
+			Name:              task.Name,
+			Difficulty:        task.Difficulty,
+			EstimatedDuration: task.EstimatedDuration,
+			Priority:          task.Priority,
+			Deadline:          task.Deadline,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update task %d: %w", id, err)
+	}
+
+	revision := model.TaskRevision{TaskID: id, ChangedBy: changedBy, Changes: changes}
+	if err := tx.Create(&revision).Error; err != nil {
+		return fmt.Errorf("failed to record revision of task %d: %w", id, err)
+	}
+
+	return nil
 }
 
 // GetTasks returns all tasks from the database
@@ -40,3 +410,329 @@ func (s *TaskService) GetTasks() ([]model.Task, error) {
 
 	return tasks, nil
 }
+
+// ListTasks returns a page of top-level tasks matching the filter together
+// with the number of all matching tasks
+func (s *TaskService) ListTasks(filter TaskFilter) ([]model.Task, int64, error) {
+	query := s.db.Model(&model.Task{}).Where("parent_id IS NULL")
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.MinDifficulty != nil {
+		query = query.Where("difficulty >= ?", *filter.MinDifficulty)
+	}
+	if filter.MaxDifficulty != nil {
+		query = query.Where("difficulty <= ?", *filter.MaxDifficulty)
+	}
+	if filter.MinDuration != nil {
+		query = query.Where("estimated_duration >= ?", *filter.MinDuration)
+	}
+	if filter.MaxDuration != nil {
+		query = query.Where("estimated_duration <= ?", *filter.MaxDuration)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultTaskLimit
+	}
+	limit = min(limit, MaxTaskLimit)
+
+	var tasks []model.Task
+	err := query.Preload("SubTasks").Order("id").Limit(limit).Offset(max(filter.Offset, 0)).Find(&tasks).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	return tasks, total, nil
+}
+
+// GetTask returns a task with its sub-tasks
+func (s *TaskService) GetTask(id uint) (*model.Task, error) {
+	var task model.Task
+	if err := s.db.Preload("SubTasks").First(&task, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get task %d: %w", id, err)
+	}
+
+	return &task, nil
+}
+
+// generatedIDPrefix starts the external ids made for manual tasks created
+// without one, explicit external ids can't use it
+const generatedIDPrefix = model.SourceManual + "-"
+
+// CreateTask stores a manually entered task. Without an external id it gets
+// one made of the task id, e.g. manual-7.
+func (s *TaskService) CreateTask(task *model.Task) error {
+	task.ID = 0
+	task.Source = model.SourceManual
+	task.ParentID = nil
+	task.Sequence = 0
+
+	if err := validateTask(task.Difficulty, task.EstimatedDuration); err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(task.ExternalID, generatedIDPrefix) {
+		return fmt.Errorf("%w: external ids starting with %s are reserved for generated ones", ErrInvalidTask, generatedIDPrefix)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		externalID := task.ExternalID
+		if externalID == "" {
+			// a placeholder keeps the source and external id unique until the id is known
+			task.ExternalID = fmt.Sprintf("pending-%d", time.Now().UnixNano())
+		} else if err := tx.Unscoped().Where("source = ? AND external_id = ?", task.Source, externalID).First(&model.Task{}).Error; err == nil {
+			return fmt.Errorf("%w: %s", ErrDuplicateTask, externalID)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to check task external id: %w", err)
+		}
+
+		if err := tx.Omit(clause.Associations).Create(task).Error; err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+
+		if externalID == "" {
+			task.ExternalID = fmt.Sprintf("%s%d", generatedIDPrefix, task.ID)
+			if err := tx.Model(task).Update("external_id", task.ExternalID).Error; err != nil {
+				return fmt.Errorf("failed to set task external id: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpdateTask changes the name, estimates, priority and deadline of a task
+func (s *TaskService) UpdateTask(id uint, update TaskUpdate) (*model.Task, error) {
+	task, err := s.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *task
+	if update.Name != nil {
+		updated.Name = update.Name
+	}
+	if update.Difficulty != nil {
+		updated.Difficulty = *update.Difficulty
+	}
+	if update.EstimatedDuration != nil {
+		updated.EstimatedDuration = *update.EstimatedDuration
+	}
+	if update.Priority != nil {
+		updated.Priority = *update.Priority
+	}
+	if update.Deadline != nil {
+		updated.Deadline = update.Deadline
+	}
+	if update.ClearDeadline {
+		updated.Deadline = nil
+	}
+
+	if err := validateTask(updated.Difficulty, updated.EstimatedDuration); err != nil {
+		return nil, err
+	}
+
+	changes := diffTask(*task, updated)
+	if len(changes) == 0 {
+		return task, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return updateTask(tx, id, updated, ChangedByAPI, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteTask soft deletes a task together with its sub-tasks so it is no
+// longer planned
+func (s *TaskService) DeleteTask(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&model.Task{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete task %d: %w", id, result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("failed to delete task %d: %w", id, gorm.ErrRecordNotFound)
+		}
+
+		if err := tx.Where("parent_id = ?", id).Delete(&model.Task{}).Error; err != nil {
+			return fmt.Errorf("failed to delete sub-tasks of task %d: %w", id, err)
+		}
+
+		return nil
+	})
+}
+
+func validateTask(difficulty, duration float64) error {
+	if difficulty < 0 {
+		return fmt.Errorf("%w: difficulty must not be negative", ErrInvalidTask)
+	}
+
+	if duration < 0 {
+		return fmt.Errorf("%w: estimated duration must not be negative", ErrInvalidTask)
+	}
+
+	return nil
+}
+
+// SplitTask stores the given number of ordered sub-tasks for a task, sharing
+// its duration equally. Sub-tasks from an earlier split are updated in place
+// and the ones no longer needed are deleted.
+func (s *TaskService) SplitTask(task model.Task, parts int) ([]model.Task, error) {
+	if parts < 2 {
+		return nil, fmt.Errorf("a task must be split into at least 2 parts, got %d", parts)
+	}
+
+	subTasks := make([]model.Task, 0, parts)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []model.Task
+		if err := tx.Unscoped().Where("parent_id = ?", task.ID).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to get sub-tasks: %w", err)
+		}
+
+		bySequence := make(map[int]model.Task, len(existing))
+		for _, subTask := range existing {
+			bySequence[subTask.Sequence] = subTask
+		}
+
+		for i := 1; i <= parts; i++ {
+			subTask := task.Part(i, parts)
+
+			if current, ok := bySequence[i]; ok {
+				subTask.ID = current.ID
+				subTask.CreatedAt = current.CreatedAt
+
+				if err := tx.Unscoped().Omit(clause.Associations).Save(&subTask).Error; err != nil {
+					return fmt.Errorf("failed to update sub-task %d: %w", i, err)
+				}
+			} else if err := tx.Omit(clause.Associations).Create(&subTask).Error; err != nil {
+				return fmt.Errorf("failed to create sub-task %d: %w", i, err)
+			}
+
+			subTasks = append(subTasks, subTask)
+		}
+
+		for sequence, subTask := range bySequence {
+			if sequence > parts && !subTask.DeletedAt.Valid {
+				if err := tx.Delete(&subTask).Error; err != nil {
+					return fmt.Errorf("failed to delete sub-task %d: %w", sequence, err)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subTasks, nil
+}
+
+// UnsplitTask deletes the sub-tasks of a task that no longer has to be split
+func (s *TaskService) UnsplitTask(task model.Task) error {
+	if err := s.db.Where("parent_id = ?", task.ID).Delete(&model.Task{}).Error; err != nil {
+		return fmt.Errorf("failed to delete sub-tasks: %w", err)
+	}
+
+	return nil
+}
+
+// GetDependencies returns all task dependencies
+func (s *TaskService) GetDependencies() ([]model.TaskDependency, error) {
+	var dependencies []model.TaskDependency
+	if err := s.db.Find(&dependencies).Error; err != nil {
+		return nil, fmt.Errorf("failed to get task dependencies: %w", err)
+	}
+
+	return dependencies, nil
+}
+
+// AddDependency records that a task can't start before another one is finished.
+// It returns ErrDependencyCycle when the other task already waits for the task.
+func (s *TaskService) AddDependency(taskID, dependsOnID uint) (*model.TaskDependency, error) {
+	if taskID == dependsOnID {
+		return nil, fmt.Errorf("%w: task %d can't depend on itself", ErrDependencyCycle, taskID)
+	}
+
+	dependency := model.TaskDependency{TaskID: taskID, DependsOnID: dependsOnID}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range []uint{taskID, dependsOnID} {
+			if err := tx.Select("id").First(&model.Task{}, id).Error; err != nil {
+				return fmt.Errorf("failed to get task %d: %w", id, err)
+			}
+		}
+
+		var dependencies []model.TaskDependency
+		if err := tx.Find(&dependencies).Error; err != nil {
+			return fmt.Errorf("failed to get task dependencies: %w", err)
+		}
+
+		prerequisites := make(map[uint][]uint)
+		for _, d := range dependencies {
+			if d.TaskID == taskID && d.DependsOnID == dependsOnID {
+				dependency = d
+				return nil
+			}
+
+			prerequisites[d.TaskID] = append(prerequisites[d.TaskID], d.DependsOnID)
+		}
+
+		// walk everything the new prerequisite waits for, reaching the task closes a cycle
+		visited := map[uint]struct{}{dependsOnID: {}}
+		queue := []uint{dependsOnID}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			for _, next := range prerequisites[current] {
+				if next == taskID {
+					return fmt.Errorf("%w: task %d already depends on task %d", ErrDependencyCycle, dependsOnID, taskID)
+				}
+
+				if _, ok := visited[next]; !ok {
+					visited[next] = struct{}{}
+					queue = append(queue, next)
+				}
+			}
+		}
+
+		if err := tx.Create(&dependency).Error; err != nil {
+			return fmt.Errorf("failed to create task dependency: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dependency, nil
+}
+
+// RemoveDependency deletes a task dependency
+func (s *TaskService) RemoveDependency(id uint) error {
+	result := s.db.Delete(&model.TaskDependency{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete task dependency: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to delete task dependency %d: %w", id, gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
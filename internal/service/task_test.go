@@ -1,16 +1,20 @@
 package service
 
 import (
+	"errors"
+	"strconv"
 	"testing"
 	"time"
 
 	"todo-planning/internal/model"
 	"todo-planning/internal/utility"
+
+	"gorm.io/gorm"
 )
 
 func setupTaskTest(t *testing.T) (*TaskService, func()) {
 	db := utility.GetTestDB()
-	utility.AutoMigrate(&model.Task{})
+	utility.AutoMigrate(&model.Task{}, &model.TaskRevision{})
 
 	service := NewTaskService(db)
 
@@ -84,7 +88,7 @@ func TestTaskService_StoreTasks(t *testing.T) {
 					UpdatedAt:         time.Now(),
 				},
 			},
-			wantErr: false, // Should not error due to ON CONFLICT DO NOTHING
+			wantErr: false, // Should not error, the stored task is unchanged
 		},
 	}
 
@@ -138,3 +142,541 @@ func TestTaskService_GetTasks(t *testing.T) {
 		t.Errorf("TaskService.GetTasks() got = %v tasks, want %v tasks", len(got), len(tasks))
 	}
 }
+
+func TestTaskService_SplitTask(t *testing.T) {
+	service, cleanup := setupTaskTest(t)
+	defer cleanup()
+
+	task := model.Task{
+		ExternalID:        "1",
+		Name:              utility.ToPointer("Big Task"),
+		Difficulty:        3.0,
+		EstimatedDuration: 90.0,
+		Source:            "test",
+	}
+	if err := service.db.Create(&task).Error; err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	subTasks, err := service.SplitTask(task, 3)
+	if err != nil {
+		t.Fatalf("TaskService.SplitTask() error = %v", err)
+	}
+	if len(subTasks) != 3 {
+		t.Fatalf("Expected 3 sub-tasks, got %d", len(subTasks))
+	}
+	for i, subTask := range subTasks {
+		if subTask.ID == 0 || subTask.ParentID == nil || *subTask.ParentID != task.ID {
+			t.Errorf("Expected sub-task %d to be stored with parent %d, got %+v", i, task.ID, subTask)
+		}
+		if subTask.Sequence != i+1 {
+			t.Errorf("Expected sequence %d, got %d", i+1, subTask.Sequence)
+		}
+		if subTask.EstimatedDuration != 30.0 {
+			t.Errorf("Expected duration 30, got %f", subTask.EstimatedDuration)
+		}
+	}
+	if *subTasks[0].Name != "Big Task (part 1/3)" {
+		t.Errorf("Expected name 'Big Task (part 1/3)', got '%s'", *subTasks[0].Name)
+	}
+
+	// splitting into fewer parts reuses the existing sub-tasks and deletes the rest
+	resplit, err := service.SplitTask(task, 2)
+	if err != nil {
+		t.Fatalf("TaskService.SplitTask() error = %v", err)
+	}
+	if resplit[0].ID != subTasks[0].ID || resplit[1].ID != subTasks[1].ID {
+		t.Errorf("Expected sub-tasks to be reused")
+	}
+	if resplit[0].EstimatedDuration != 45.0 {
+		t.Errorf("Expected duration 45, got %f", resplit[0].EstimatedDuration)
+	}
+
+	var count int64
+	if err := service.db.Model(&model.Task{}).Where("parent_id = ?", task.ID).Count(&count).Error; err != nil {
+		t.Fatalf("Failed to count sub-tasks: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 sub-tasks, got %d", count)
+	}
+
+	// splitting into more parts again restores the deleted sub-task
+	restored, err := service.SplitTask(task, 3)
+	if err != nil {
+		t.Fatalf("TaskService.SplitTask() error = %v", err)
+	}
+	if restored[2].ID != subTasks[2].ID {
+		t.Errorf("Expected deleted sub-task to be restored")
+	}
+
+	// a task that fits into a week again loses its sub-tasks
+	if err := service.UnsplitTask(task); err != nil {
+		t.Fatalf("TaskService.UnsplitTask() error = %v", err)
+	}
+	remaining, err := service.GetTasks()
+	if err != nil {
+		t.Fatalf("TaskService.GetTasks() error = %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != task.ID {
+		t.Errorf("TaskService.GetTasks() got = %v tasks, want only the task itself", len(remaining))
+	}
+
+	// and gets them back when it has to be split again
+	if resplit, err = service.SplitTask(task, 2); err != nil {
+		t.Fatalf("TaskService.SplitTask() error = %v", err)
+	}
+	if resplit[0].ID != subTasks[0].ID {
+		t.Errorf("Expected deleted sub-task to be restored")
+	}
+
+	if _, err := service.SplitTask(task, 1); err == nil {
+		t.Error("Expected error when splitting into a single part")
+	}
+}
+
+func TestTaskService_AddDependency(t *testing.T) {
+	db := utility.GetTestDB()
+	utility.AutoMigrate(&model.Task{}, &model.TaskDependency{})
+
+	service := NewTaskService(db)
+	defer func() {
+		utility.ClearTables()
+		utility.CloseTestDB()
+	}()
+
+	tasks := []model.Task{
+		{ExternalID: "1", Source: "test"},
+		{ExternalID: "2", Source: "test"},
+		{ExternalID: "3", Source: "test"},
+	}
+	if err := service.StoreTasks(tasks); err != nil {
+		t.Fatalf("Failed to store tasks: %v", err)
+	}
+
+	stored, err := service.GetTasks()
+	if err != nil {
+		t.Fatalf("Failed to get tasks: %v", err)
+	}
+	a, b, c := stored[0].ID, stored[1].ID, stored[2].ID
+
+	first, err := service.AddDependency(b, a)
+	if err != nil {
+		t.Fatalf("TaskService.AddDependency() error = %v", err)
+	}
+	if _, err := service.AddDependency(c, b); err != nil {
+		t.Fatalf("TaskService.AddDependency() error = %v", err)
+	}
+
+	duplicate, err := service.AddDependency(b, a)
+	if err != nil {
+		t.Fatalf("TaskService.AddDependency() error = %v", err)
+	}
+	if duplicate.ID != first.ID {
+		t.Errorf("Expected existing dependency %d to be returned, got %d", first.ID, duplicate.ID)
+	}
+
+	if _, err := service.AddDependency(a, c); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("Expected cycle error, got %v", err)
+	}
+	if _, err := service.AddDependency(a, a); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("Expected cycle error for self dependency, got %v", err)
+	}
+	if _, err := service.AddDependency(a, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected record not found error, got %v", err)
+	}
+
+	dependencies, err := service.GetDependencies()
+	if err != nil {
+		t.Fatalf("TaskService.GetDependencies() error = %v", err)
+	}
+	if len(dependencies) != 2 {
+		t.Fatalf("Expected 2 dependencies, got %d", len(dependencies))
+	}
+
+	if err := service.RemoveDependency(first.ID); err != nil {
+		t.Fatalf("TaskService.RemoveDependency() error = %v", err)
+	}
+	if err := service.RemoveDependency(first.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected record not found error, got %v", err)
+	}
+
+	// without b -> a the cycle is gone
+	if _, err := service.AddDependency(a, c); err != nil {
+		t.Errorf("TaskService.AddDependency() error = %v", err)
+	}
+}
+
+func TestTaskService_CreateTask(t *testing.T) {
+	service, cleanup := setupTaskTest(t)
+	defer cleanup()
+
+	task := &model.Task{Name: utility.ToPointer("Manual Task"), Difficulty: 2, EstimatedDuration: 5, Source: "mock-one"}
+	if err := service.CreateTask(task); err != nil {
+		t.Fatalf("TaskService.CreateTask() error = %v", err)
+	}
+
+	if task.Source != model.SourceManual {
+		t.Errorf("TaskService.CreateTask() got.Source = %v, want %v", task.Source, model.SourceManual)
+	}
+
+	got, err := service.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("TaskService.GetTask() error = %v", err)
+	}
+	if want := "manual-" + strconv.FormatUint(uint64(task.ID), 10); got.ExternalID != want {
+		t.Errorf("TaskService.GetTask() got.ExternalID = %v, want %v", got.ExternalID, want)
+	}
+
+	if err := service.CreateTask(&model.Task{ExternalID: "MAN-1", Difficulty: 1, EstimatedDuration: 1}); err != nil {
+		t.Fatalf("TaskService.CreateTask() error = %v", err)
+	}
+
+	// an explicit external id that looks like the next task id
+	next := strconv.FormatUint(uint64(task.ID+3), 10)
+	if err := service.CreateTask(&model.Task{ExternalID: next, Difficulty: 1, EstimatedDuration: 1}); err != nil {
+		t.Fatalf("TaskService.CreateTask() error = %v", err)
+	}
+	generated := &model.Task{Difficulty: 1, EstimatedDuration: 1}
+	if err := service.CreateTask(generated); err != nil {
+		t.Fatalf("TaskService.CreateTask() error = %v", err)
+	}
+	if generated.ID != task.ID+3 || generated.ExternalID != "manual-"+strconv.FormatUint(uint64(generated.ID), 10) {
+		t.Errorf("TaskService.CreateTask() got = %+v, want task %d with its own external id", generated, task.ID+3)
+	}
+
+	tests := []struct {
+		name    string
+		task    *model.Task
+		wantErr error
+	}{
+		{
+			name:    "negative difficulty",
+			task:    &model.Task{Difficulty: -1, EstimatedDuration: 1},
+			wantErr: ErrInvalidTask,
+		},
+		{
+			name:    "negative duration",
+			task:    &model.Task{Difficulty: 1, EstimatedDuration: -1},
+			wantErr: ErrInvalidTask,
+		},
+		{
+			name:    "duplicate external id",
+			task:    &model.Task{ExternalID: "MAN-1", Difficulty: 1, EstimatedDuration: 1},
+			wantErr: ErrDuplicateTask,
+		},
+		{
+			name:    "generated external id",
+			task:    &model.Task{ExternalID: "manual-99", Difficulty: 1, EstimatedDuration: 1},
+			wantErr: ErrInvalidTask,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.CreateTask(tt.task); !errors.Is(err, tt.wantErr) {
+				t.Errorf("TaskService.CreateTask() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaskService_ListTasks(t *testing.T) {
+	service, cleanup := setupTaskTest(t)
+	defer cleanup()
+
+	tasks := []model.Task{
+		{ExternalID: "1", Source: "mock-one", Difficulty: 1, EstimatedDuration: 2},
+		{ExternalID: "2", Source: "mock-one", Difficulty: 3, EstimatedDuration: 8},
+		{ExternalID: "3", Source: "mock-one", Difficulty: 5, EstimatedDuration: 12},
+		{ExternalID: "1", Source: "mock-two", Difficulty: 4, EstimatedDuration: 4},
+	}
+	if err := service.StoreTasks(tasks); err != nil {
+		t.Fatalf("TaskService.StoreTasks() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		filter    TaskFilter
+		wantIDs   []string
+		wantTotal int64
+	}{
+		{
+			name:      "all tasks",
+			filter:    TaskFilter{},
+			wantIDs:   []string{"mock-one-1", "mock-one-2", "mock-one-3", "mock-two-1"},
+			wantTotal: 4,
+		},
+		{
+			name:      "by source",
+			filter:    TaskFilter{Source: "mock-two"},
+			wantIDs:   []string{"mock-two-1"},
+			wantTotal: 1,
+		},
+		{
+			name:      "by difficulty and duration",
+			filter:    TaskFilter{MinDifficulty: utility.ToPointer(3.0), MaxDuration: utility.ToPointer(10.0)},
+			wantIDs:   []string{"mock-one-2", "mock-two-1"},
+			wantTotal: 2,
+		},
+		{
+			name:      "paginated",
+			filter:    TaskFilter{Source: "mock-one", Limit: 2, Offset: 1},
+			wantIDs:   []string{"mock-one-2", "mock-one-3"},
+			wantTotal: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := service.ListTasks(tt.filter)
+			if err != nil {
+				t.Fatalf("TaskService.ListTasks() error = %v", err)
+			}
+
+			if total != tt.wantTotal {
+				t.Errorf("TaskService.ListTasks() total = %v, want %v", total, tt.wantTotal)
+			}
+
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("TaskService.ListTasks() got = %v tasks, want %v tasks", len(got), len(tt.wantIDs))
+			}
+
+			for i, task := range got {
+				if id := task.Source + "-" + task.ExternalID; id != tt.wantIDs[i] {
+					t.Errorf("TaskService.ListTasks() got[%d] = %v, want %v", i, id, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTaskService_UpdateDeleteTask(t *testing.T) {
+	service, cleanup := setupTaskTest(t)
+	defer cleanup()
+
+	task := &model.Task{Name: utility.ToPointer("Manual Task"), Difficulty: 2, EstimatedDuration: 5}
+	if err := service.CreateTask(task); err != nil {
+		t.Fatalf("TaskService.CreateTask() error = %v", err)
+	}
+
+	got, err := service.UpdateTask(task.ID, TaskUpdate{EstimatedDuration: utility.ToPointer(8.0)})
+	if err != nil {
+		t.Fatalf("TaskService.UpdateTask() error = %v", err)
+	}
+	if got.EstimatedDuration != 8 || got.Difficulty != 2 || got.DisplayName() != "Manual Task" {
+		t.Errorf("TaskService.UpdateTask() got = %+v, want duration 8 with the other fields kept", got)
+	}
+
+	if _, err := service.UpdateTask(task.ID, TaskUpdate{Difficulty: utility.ToPointer(-1.0)}); !errors.Is(err, ErrInvalidTask) {
+		t.Errorf("TaskService.UpdateTask() error = %v, want %v", err, ErrInvalidTask)
+	}
+
+	if _, err := service.SplitTask(*got, 2); err != nil {
+		t.Fatalf("TaskService.SplitTask() error = %v", err)
+	}
+
+	if err := service.DeleteTask(task.ID); err != nil {
+		t.Fatalf("TaskService.DeleteTask() error = %v", err)
+	}
+	if err := service.DeleteTask(task.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("TaskService.DeleteTask() error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+
+	remaining, err := service.GetTasks()
+	if err != nil {
+		t.Fatalf("TaskService.GetTasks() error = %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("TaskService.GetTasks() got = %v tasks, want the task and its sub-tasks deleted", len(remaining))
+	}
+}
+
+func TestTaskService_UpsertTasks(t *testing.T) {
+	service, cleanup := setupTaskTest(t)
+	defer cleanup()
+
+	tasks := []model.Task{
+		{ExternalID: "1", Source: "mock-one", Name: utility.ToPointer("Task 1"), Difficulty: 1, EstimatedDuration: 2},
+		{ExternalID: "2", Source: "mock-one", Name: utility.ToPointer("Task 2"), Difficulty: 2, EstimatedDuration: 4},
+		{ExternalID: "1", Source: "mock-two", Name: utility.ToPointer("Task 1"), Difficulty: 3, EstimatedDuration: 6},
+	}
+
+	summary, err := service.UpsertTasks(tasks)
+	if err != nil {
+		t.Fatalf("TaskService.UpsertTasks() error = %v", err)
+	}
+	if summary != (StoreSummary{Inserted: 3}) {
+		t.Errorf("TaskService.UpsertTasks() got = %+v, want 3 inserted", summary)
+	}
+
+	// a deleted task isn't brought back or changed
+	stored, _, err := service.ListTasks(TaskFilter{Source: "mock-two"})
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("TaskService.ListTasks() got = %v, %v", stored, err)
+	}
+	if err := service.DeleteTask(stored[0].ID); err != nil {
+		t.Fatalf("TaskService.DeleteTask() error = %v", err)
+	}
+
+	changed := []model.Task{
+		{ExternalID: "1", Source: "mock-one", Name: utility.ToPointer("Task 1"), Difficulty: 1, EstimatedDuration: 2},
+		{ExternalID: "2", Source: "mock-one", Name: utility.ToPointer("Task 2 renamed"), Difficulty: 2, EstimatedDuration: 4},
+		{ExternalID: "2", Source: "mock-one", Name: utility.ToPointer("Task 2 renamed"), Difficulty: 5, EstimatedDuration: 4},
+		{ExternalID: "1", Source: "mock-two", Name: utility.ToPointer("Task 1"), Difficulty: 9, EstimatedDuration: 9},
+		{ExternalID: "3", Source: "mock-one", Name: utility.ToPointer("Task 3"), Difficulty: 1, EstimatedDuration: 1},
+	}
+
+	summary, err = service.UpsertTasks(changed)
+	if err != nil {
+		t.Fatalf("TaskService.UpsertTasks() error = %v", err)
+	}
+	if want := (StoreSummary{Inserted: 1, Updated: 1, Unchanged: 2}); summary != want {
+		t.Errorf("TaskService.UpsertTasks() got = %+v, want %+v", summary, want)
+	}
+
+	tasksByID, _, err := service.ListTasks(TaskFilter{Source: "mock-one"})
+	if err != nil {
+		t.Fatalf("TaskService.ListTasks() error = %v", err)
+	}
+	if len(tasksByID) != 3 {
+		t.Fatalf("TaskService.ListTasks() got = %v tasks, want 3 tasks", len(tasksByID))
+	}
+
+	updated := tasksByID[1]
+	if updated.DisplayName() != "Task 2 renamed" || updated.Difficulty != 5 {
+		t.Errorf("TaskService.UpsertTasks() stored = %+v, want renamed task with difficulty 5", updated)
+	}
+
+	revisions, err := service.GetRevisions(updated.ID)
+	if err != nil {
+		t.Fatalf("TaskService.GetRevisions() error = %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].ChangedBy != "mock-one" || len(revisions[0].Changes) != 2 {
+		t.Fatalf("TaskService.GetRevisions() got = %+v, want one revision with 2 changes", revisions)
+	}
+	if change := revisions[0].Changes[1]; change.Field != "difficulty" || change.OldValue != 2.0 || change.NewValue != 5.0 {
+		t.Errorf("TaskService.GetRevisions() got change = %+v, want difficulty from 2 to 5", change)
+	}
+
+	// updates through the API are recorded as well
+	if _, err := service.UpdateTask(updated.ID, TaskUpdate{EstimatedDuration: utility.ToPointer(8.0)}); err != nil {
+		t.Fatalf("TaskService.UpdateTask() error = %v", err)
+	}
+
+	revisions, err = service.GetRevisions(updated.ID)
+	if err != nil {
+		t.Fatalf("TaskService.GetRevisions() error = %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].ChangedBy != ChangedByAPI {
+		t.Errorf("TaskService.GetRevisions() got = %+v, want the api revision first", revisions)
+	}
+
+	// priorities and deadlines set through the API are kept by provider syncs
+	deadline := time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC)
+	if _, err := service.UpdateTask(updated.ID, TaskUpdate{Priority: utility.ToPointer(3), Deadline: &deadline}); err != nil {
+		t.Fatalf("TaskService.UpdateTask() error = %v", err)
+	}
+
+	summary, err = service.UpsertTasks([]model.Task{
+		{ExternalID: "2", Source: "mock-one", Name: utility.ToPointer("Task 2 renamed"), Difficulty: 5, EstimatedDuration: 8},
+	})
+	if err != nil {
+		t.Fatalf("TaskService.UpsertTasks() error = %v", err)
+	}
+	if want := (StoreSummary{Unchanged: 1}); summary != want {
+		t.Errorf("TaskService.UpsertTasks() got = %+v, want %+v", summary, want)
+	}
+
+	kept, err := service.GetTask(updated.ID)
+	if err != nil {
+		t.Fatalf("TaskService.GetTask() error = %v", err)
+	}
+	if kept.Priority != 3 || kept.Deadline == nil || !kept.Deadline.Equal(deadline) {
+		t.Errorf("TaskService.UpsertTasks() stored = %+v, want priority 3 and deadline %v kept", kept, deadline)
+	}
+
+	if _, err := service.UpdateTask(updated.ID, TaskUpdate{ClearDeadline: true}); err != nil {
+		t.Fatalf("TaskService.UpdateTask() error = %v", err)
+	}
+
+	cleared, err := service.GetTask(updated.ID)
+	if err != nil {
+		t.Fatalf("TaskService.GetTask() error = %v", err)
+	}
+	if cleared.Deadline != nil || cleared.Priority != 3 {
+		t.Errorf("TaskService.UpdateTask() got = %+v, want the deadline removed and priority 3 kept", cleared)
+	}
+}
+
+func TestTaskService_ReconcileTasks(t *testing.T) {
+	service, cleanup := setupTaskTest(t)
+	defer cleanup()
+
+	tasks := []model.Task{
+		{ExternalID: "1", Source: "mock-one", Name: utility.ToPointer("Task 1"), Difficulty: 1, EstimatedDuration: 2},
+		{ExternalID: "2", Source: "mock-one", Name: utility.ToPointer("Task 2"), Difficulty: 1, EstimatedDuration: 60},
+		{ExternalID: "1", Source: "mock-two", Name: utility.ToPointer("Task 1"), Difficulty: 1, EstimatedDuration: 2},
+	}
+	if _, err := service.UpsertTasks(tasks); err != nil {
+		t.Fatalf("TaskService.UpsertTasks() error = %v", err)
+	}
+
+	stored, _, err := service.ListTasks(TaskFilter{Source: "mock-one"})
+	if err != nil {
+		t.Fatalf("TaskService.ListTasks() error = %v", err)
+	}
+	if _, err := service.SplitTask(stored[1], 2); err != nil {
+		t.Fatalf("TaskService.SplitTask() error = %v", err)
+	}
+
+	// within the grace period the task is only marked missing
+	summary, err := service.ReconcileTasks("mock-one", []string{"1"}, time.Hour)
+	if err != nil {
+		t.Fatalf("TaskService.ReconcileTasks() error = %v", err)
+	}
+	if summary.Missing != 1 || len(summary.Removed) != 0 {
+		t.Errorf("TaskService.ReconcileTasks() got = %+v, want 1 missing task", summary)
+	}
+
+	missing, err := service.GetTask(stored[1].ID)
+	if err != nil {
+		t.Fatalf("TaskService.GetTask() error = %v", err)
+	}
+	if missing.MissingSince == nil {
+		t.Errorf("TaskService.GetTask() got = %+v, want task marked missing", missing)
+	}
+
+	summary, err = service.ReconcileTasks("mock-one", []string{"1"}, 0)
+	if err != nil {
+		t.Fatalf("TaskService.ReconcileTasks() error = %v", err)
+	}
+	if summary.Missing != 0 || len(summary.Removed) != 1 || summary.Removed[0].ExternalID != "2" {
+		t.Fatalf("TaskService.ReconcileTasks() got = %+v, want task 2 removed", summary)
+	}
+
+	// the task, its sub-tasks and nothing of the other source are deleted
+	remaining, err := service.GetTasks()
+	if err != nil {
+		t.Fatalf("TaskService.GetTasks() error = %v", err)
+	}
+	if len(remaining) != 2 {
+		t.Errorf("TaskService.GetTasks() got = %v tasks, want 2 tasks", len(remaining))
+	}
+
+	// a removed task that comes back is restored
+	upsert, err := service.UpsertTasks(tasks[:2])
+	if err != nil {
+		t.Fatalf("TaskService.UpsertTasks() error = %v", err)
+	}
+	if want := (StoreSummary{Unchanged: 1, Restored: 1}); upsert != want {
+		t.Errorf("TaskService.UpsertTasks() got = %+v, want %+v", upsert, want)
+	}
+
+	restored, err := service.GetTask(stored[1].ID)
+	if err != nil {
+		t.Fatalf("TaskService.GetTask() error = %v", err)
+	}
+	if restored.MissingSince != nil || restored.RemovedUpstream {
+		t.Errorf("TaskService.GetTask() got = %+v, want the missing mark cleared", restored)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-planning/internal/config"
+	"todo-planning/internal/logger"
+	"todo-planning/internal/model"
+	"todo-planning/internal/provider"
+)
+
+var (
+	ErrUnknownWebhook   = errors.New("no webhook configured for source")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+)
+
+// Webhook headers
+const (
+	DefaultSignatureHeader = "X-Signature-256"     // hex HMAC-SHA256 of the timestamp and body, optionally prefixed with sha256=
+	WebhookEventHeader     = "X-Webhook-Event"     // create, update or delete
+	WebhookTimestampHeader = "X-Webhook-Timestamp" // unix seconds the event was sent at
+)
+
+// DefaultWebhookTolerance is how far the timestamp of an event may be off
+// the clock of the planner, older events are taken for replays
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// Webhook is a source that may push changes of its tasks
+type Webhook struct {
+	Provider  provider.WebhookProvider // reads the payloads
+	Secret    []byte
+	Header    string        // header carrying the signature
+	Tolerance time.Duration // how far the signed timestamp may be off
+	Replan    bool          // create a new plan after a change
+}
+
+// WebhookResult reports what an event changed
+type WebhookResult struct {
+	Source  string              `json:"source"`
+	Event   string              `json:"event"`
+	Tasks   int                 `json:"tasks"`
+	Stored  *StoreSummary       `json:"stored,omitempty"`  // create and update events
+	Removed []model.RemovedTask `json:"removed,omitempty"` // delete events
+	Replan  bool                `json:"replan"`            // a new plan is created because tasks changed
+}
+
+// WebhookService applies the changes pushed by the sources of providers
+type WebhookService struct {
+	taskService *TaskService
+	webhooks    map[string]Webhook // by source
+}
+
+// NewWebhookService accepts the webhooks of the provider instances in
+// config.yaml that have a webhook secret
+func NewWebhookService(taskService *TaskService, providerService *ProviderService) *WebhookService {
+	webhooks := make(map[string]Webhook)
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error(err)
+		return newWebhookService(taskService, webhooks)
+	}
+
+	for _, instance := range cfg.Providers {
+		if !instance.IsEnabled() || !instance.Webhook.Enabled() {
+			continue
+		}
+
+		name := instance.Name
+		if name == "" {
+			name = instance.Type
+		}
+
+		webhook, err := newWebhook(providerService.Provider(name), instance.Webhook)
+		if err != nil {
+			logger.Error(fmt.Errorf("webhook of provider %s: %w", name, err))
+			continue
+		}
+
+		webhooks[name] = webhook
+	}
+
+	return newWebhookService(taskService, webhooks)
+}
+
+func newWebhookService(taskService *TaskService, webhooks map[string]Webhook) *WebhookService {
+	return &WebhookService{
+		taskService: taskService,
+		webhooks:    webhooks,
+	}
+}
+
+func newWebhook(p provider.Provider, webhookConfig config.WebhookConfig) (Webhook, error) {
+	if p == nil {
+		return Webhook{}, fmt.Errorf("provider isn't available")
+	}
+
+	webhookProvider, ok := p.(provider.WebhookProvider)
+	if !ok {
+		return Webhook{}, fmt.Errorf("provider can't read webhooks")
+	}
+
+	secret, err := webhookConfig.Secret.Resolve()
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	header := webhookConfig.Header
+	if header == "" {
+		header = DefaultSignatureHeader
+	}
+
+	tolerance := webhookConfig.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+
+	return Webhook{
+		Provider:  webhookProvider,
+		Secret:    []byte(secret),
+		Header:    header,
+		Tolerance: tolerance,
+		Replan:    webhookConfig.Replan,
+	}, nil
+}
+
+// Handle verifies the signature and timestamp of an event pushed by a
+// source and applies it. Create and update events both insert new tasks and
+// update known ones, delete events remove the tasks like a sync that no
+// longer finds them.
+func (s *WebhookService) Handle(source string, header http.Header, body []byte) (*WebhookResult, error) {
+	webhook, ok := s.webhooks[source]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownWebhook, source)
+	}
+
+	timestamp := strings.TrimSpace(header.Get(WebhookTimestampHeader))
+	if !validSignature(webhook.Secret, header.Get(webhook.Header), timestamp, body) {
+		return nil, ErrInvalidSignature
+	}
+
+	// the timestamp is signed, so an event can't be replayed once it is too old
+	if err := checkTimestamp(timestamp, webhook.Tolerance, time.Now()); err != nil {
+		return nil, err
+	}
+
+	event := strings.ToLower(strings.TrimSpace(header.Get(WebhookEventHeader)))
+	if event != provider.EventCreate && event != provider.EventUpdate && event != provider.EventDelete {
+		return nil, fmt.Errorf("%w: unknown event %q in %s, expected create, update or delete", ErrInvalidWebhook, event, WebhookEventHeader)
+	}
+
+	tasks, err := webhook.Provider.ParseWebhook(event, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebhook, err)
+	}
+
+	externalIDs := make([]string, len(tasks))
+	for i := range tasks {
+		if tasks[i].ExternalID == "" {
+			return nil, fmt.Errorf("%w: task %d has no id", ErrInvalidWebhook, i)
+		}
+
+		if event != provider.EventDelete {
+			if err := validateTask(tasks[i].Difficulty, tasks[i].EstimatedDuration); err != nil {
+				return nil, fmt.Errorf("task %s: %w", tasks[i].ExternalID, err)
+			}
+		}
+
+		tasks[i].Source = source
+		externalIDs[i] = tasks[i].ExternalID
+	}
+
+	result := &WebhookResult{Source: source, Event: event, Tasks: len(tasks)}
+	changed := false
+
+	if event == provider.EventDelete {
+		if result.Removed, err = s.taskService.RemoveTasks(source, externalIDs); err != nil {
+			return nil, err
+		}
+		changed = len(result.Removed) > 0
+	} else {
+		summary, err := s.taskService.UpsertTasks(tasks)
+		if err != nil {
+			return nil, err
+		}
+		result.Stored = &summary
+		changed = summary.Inserted+summary.Updated+summary.Restored > 0
+	}
+
+	result.Replan = webhook.Replan && changed
+
+	return result, nil
+}
+
+// Signature returns the hex HMAC-SHA256 of the timestamp of an event, a dot
+// and its body
+func Signature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validSignature(secret []byte, signature, timestamp string, body []byte) bool {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+
+	got, err := hex.DecodeString(signature)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+
+	want, _ := hex.DecodeString(Signature(secret, timestamp, body))
+
+	return hmac.Equal(got, want)
+}
+
+// checkTimestamp refuses events sent more than tolerance before or after now
+func checkTimestamp(timestamp string, tolerance time.Duration, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s must be unix seconds, got %q", ErrInvalidSignature, WebhookTimestampHeader, timestamp)
+	}
+
+	sent := time.Unix(seconds, 0)
+	if sent.Before(now.Add(-tolerance)) || sent.After(now.Add(tolerance)) {
+		return fmt.Errorf("%w: event sent at %s is outside the tolerance of %s", ErrInvalidSignature, sent.UTC().Format(time.RFC3339), tolerance)
+	}
+
+	return nil
+}
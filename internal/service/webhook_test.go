@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"todo-planning/internal/model"
+	"todo-planning/internal/provider"
+	"todo-planning/internal/utility"
+)
+
+func setupWebhookTest(t *testing.T, replan bool) (*WebhookService, *TaskService, func()) {
+	db := utility.GetTestDB()
+	utility.AutoMigrate(&model.Task{}, &model.TaskRevision{})
+
+	taskService := NewTaskService(db)
+	client := provider.NewMockOneClient("")
+	service := newWebhookService(taskService, map[string]Webhook{
+		"mock-one": {Provider: client, Secret: []byte("secret"), Header: DefaultSignatureHeader, Tolerance: DefaultWebhookTolerance, Replan: replan},
+	})
+
+	// Return cleanup function
+	cleanup := func() {
+		utility.ClearTables()
+		utility.CloseTestDB()
+	}
+
+	return service, taskService, cleanup
+}
+
+// signed returns the headers of a webhook event sent now and signed with secret
+func signed(event, secret, body string) http.Header {
+	return signedAt(time.Now(), event, secret, body)
+}
+
+// signedAt returns the headers of a webhook event sent at the given time
+func signedAt(sent time.Time, event, secret, body string) http.Header {
+	timestamp := strconv.FormatInt(sent.Unix(), 10)
+
+	header := http.Header{}
+	header.Set(WebhookEventHeader, event)
+	header.Set(WebhookTimestampHeader, timestamp)
+	header.Set(DefaultSignatureHeader, "sha256="+Signature([]byte(secret), timestamp, []byte(body)))
+
+	return header
+}
+
+func TestWebhookService_Handle(t *testing.T) {
+	service, taskService, cleanup := setupWebhookTest(t, true)
+	defer cleanup()
+
+	events := []struct {
+		name        string
+		event       string
+		body        string
+		wantTasks   int
+		wantStored  *StoreSummary
+		wantRemoved int
+		wantReplan  bool
+	}{
+		{
+			name:       "create",
+			event:      "create",
+			body:       `[{"id": 1, "value": 2, "estimated_duration": 3}, {"id": 2, "value": 1, "estimated_duration": 1}]`,
+			wantTasks:  2,
+			wantStored: &StoreSummary{Inserted: 2},
+			wantReplan: true,
+		},
+		{
+			name:       "update",
+			event:      "Update",
+			body:       `{"id": 1, "value": 4, "estimated_duration": 3}`,
+			wantTasks:  1,
+			wantStored: &StoreSummary{Updated: 1},
+			wantReplan: true,
+		},
+		{
+			name:       "unchanged update",
+			event:      "update",
+			body:       `{"id": 1, "value": 4, "estimated_duration": 3}`,
+			wantTasks:  1,
+			wantStored: &StoreSummary{Unchanged: 1},
+			wantReplan: false,
+		},
+		{
+			name:        "delete",
+			event:       "delete",
+			body:        `[{"id": 2}, {"id": 99}]`,
+			wantTasks:   2,
+			wantRemoved: 1,
+			wantReplan:  true,
+		},
+	}
+
+	for _, tt := range events {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Handle("mock-one", signed(tt.event, "secret", tt.body), []byte(tt.body))
+			if err != nil {
+				t.Fatalf("WebhookService.Handle() error = %v", err)
+			}
+
+			if result.Tasks != tt.wantTasks || len(result.Removed) != tt.wantRemoved || result.Replan != tt.wantReplan {
+				t.Errorf("WebhookService.Handle() got = %+v, want %d tasks, %d removed and replan %v", result, tt.wantTasks, tt.wantRemoved, tt.wantReplan)
+			}
+			if tt.wantStored != nil && (result.Stored == nil || *result.Stored != *tt.wantStored) {
+				t.Errorf("WebhookService.Handle() stored = %+v, want %+v", result.Stored, tt.wantStored)
+			}
+		})
+	}
+
+	tasks, err := taskService.GetTasks()
+	if err != nil {
+		t.Fatalf("TaskService.GetTasks() error = %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ExternalID != "1" || tasks[0].Difficulty != 4 || tasks[0].Source != "mock-one" {
+		t.Errorf("Expected task 1 of mock-one with difficulty 4 to remain, got %+v", tasks)
+	}
+
+	// a deleted task comes back with the next create
+	body := `{"id": 2, "value": 1, "estimated_duration": 1}`
+	result, err := service.Handle("mock-one", signed("create", "secret", body), []byte(body))
+	if err != nil || result.Stored.Restored != 1 {
+		t.Errorf("Expected the deleted task to be restored, got %+v, %v", result, err)
+	}
+}
+
+func TestWebhookService_HandleInvalid(t *testing.T) {
+	service, _, cleanup := setupWebhookTest(t, false)
+	defer cleanup()
+
+	body := `{"id": 1, "value": 2, "estimated_duration": 3}`
+	tamperedHeader := signed("create", "secret", body)
+
+	// a captured event sent again with a fresh timestamp
+	replayedHeader := signedAt(time.Now().Add(-time.Hour), "create", "secret", body)
+	replayedHeader.Set(WebhookTimestampHeader, strconv.FormatInt(time.Now().Unix(), 10))
+
+	missingTimestamp := signed("create", "secret", body)
+	missingTimestamp.Del(WebhookTimestampHeader)
+
+	tests := []struct {
+		name    string
+		source  string
+		header  http.Header
+		body    string
+		wantErr error
+	}{
+		{name: "unknown source", source: "mock-two", header: signed("create", "secret", body), body: body, wantErr: ErrUnknownWebhook},
+		{name: "wrong secret", source: "mock-one", header: signed("create", "other", body), body: body, wantErr: ErrInvalidSignature},
+		{name: "missing signature", source: "mock-one", header: http.Header{WebhookEventHeader: {"create"}}, body: body, wantErr: ErrInvalidSignature},
+		{name: "tampered body", source: "mock-one", header: tamperedHeader, body: `{"id": 1, "value": 9, "estimated_duration": 3}`, wantErr: ErrInvalidSignature},
+		{name: "missing timestamp", source: "mock-one", header: missingTimestamp, body: body, wantErr: ErrInvalidSignature},
+		{name: "expired timestamp", source: "mock-one", header: signedAt(time.Now().Add(-time.Hour), "create", "secret", body), body: body, wantErr: ErrInvalidSignature},
+		{name: "future timestamp", source: "mock-one", header: signedAt(time.Now().Add(time.Hour), "create", "secret", body), body: body, wantErr: ErrInvalidSignature},
+		{name: "replayed with a new timestamp", source: "mock-one", header: replayedHeader, body: body, wantErr: ErrInvalidSignature},
+		{name: "unknown event", source: "mock-one", header: signed("rename", "secret", body), body: body, wantErr: ErrInvalidWebhook},
+		{name: "malformed payload", source: "mock-one", header: signed("create", "secret", "{"), body: "{", wantErr: ErrInvalidWebhook},
+		{
+			name:    "negative estimate",
+			source:  "mock-one",
+			header:  signed("create", "secret", `{"id": 1, "value": 2, "estimated_duration": -3}`),
+			body:    `{"id": 1, "value": 2, "estimated_duration": -3}`,
+			wantErr: ErrInvalidTask,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Handle(tt.source, tt.header, []byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("WebhookService.Handle() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
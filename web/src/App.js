@@ -5,9 +5,16 @@ function App() {
   const [assignments, setAssignments] = useState([]);
   const [totalHours, setTotalHours] = useState(0);
   const [totalWeeks, setTotalWeeks] = useState(0);
+  const [unassigned, setUnassigned] = useState([]);
   const [error, setError] = useState(null);
   const [isLoading, setIsLoading] = useState(true);
 
+  const unassignedReasons = {
+    no_developers: 'No developers available',
+    zero_productivity: 'No developer has any productivity',
+    exceeds_capacity: 'Exceeds weekly capacity of every developer',
+  };
+
   const formatHours = (decimalHours) => {
     const hours = Math.floor(decimalHours);
     const minutes = Math.round((decimalHours - hours) * 60);
@@ -37,6 +44,7 @@ function App() {
         setAssignments(data.assignments);
         setTotalHours(data.totalHours);
         setTotalWeeks(data.totalWeeks);
+        setUnassigned(data.unassigned || []);
       } catch (err) {
         if (err.name === 'AbortError') {
           console.log('Fetch aborted');
@@ -116,6 +124,21 @@ function App() {
           );
         })}
       </div>
+      {unassigned.length > 0 && (
+        <div className="unassigned-container">
+          <div className="unassigned-header">Unassigned Tasks</div>
+          {unassigned.map((item, index) => (
+            <div key={index} className="assignment">
+              <div className="task-info">
+                {item.task.name || `Task ${item.task.source} - ${item.task.external_id}`}
+                <span className="task-details">
+                  ({unassignedReasons[item.reason] || item.reason}, needs productivity {item.min_productivity.toFixed(2)})
+                </span>
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
       <div className="summary">
         <p>Total Time: {formatHours(totalHours)}</p>
         <p>Total Weeks: {totalWeeks}</p>